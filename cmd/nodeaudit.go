@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"github.com/ismailtsdln/HardenaK8s/internal/logger"
+	"github.com/ismailtsdln/HardenaK8s/internal/policy"
+	"github.com/ismailtsdln/HardenaK8s/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// nodeAuditCmd represents the node-audit command
+var nodeAuditCmd = &cobra.Command{
+	Use:   "node-audit",
+	Short: "Audit a node's Kubernetes files and kubelet configuration",
+	Long: `The node-audit command runs on a node, or in a privileged DaemonSet with the
+host filesystem mounted, and checks the CIS items that need file access: permissions
+and ownership of kubeconfigs, manifests and PKI files, and the kubelet configuration
+together with the flags its systemd unit passes, which take precedence over it.
+The result uses the same format as 'hardena scan', so 'hardena report' can merge it
+with the cluster scan.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root, _ := cmd.Flags().GetString("root")
+		nodeName, _ := cmd.Flags().GetString("node-name")
+		cluster, _ := cmd.Flags().GetString("cluster")
+		kubeletConfig, _ := cmd.Flags().GetString("kubelet-config")
+
+		if nodeName == "" {
+			nodeName = os.Getenv("NODE_NAME")
+		}
+		if nodeName == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				fmt.Println(ui.Error("Could not determine the node name, pass --node-name: " + err.Error()))
+				os.Exit(1)
+			}
+			nodeName = hostname
+		}
+
+		if _, err := os.Stat(root); err != nil {
+			fmt.Println(ui.Error("Cannot read the node root filesystem: " + err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Println(ui.StyleHeader.Render("Auditing Node " + nodeName + "..."))
+
+		snap := &k8s.Snapshot{Cluster: cluster, CapturedAt: time.Now().UTC()}
+		// Flags in the kubelet's systemd unit override its configuration
+		// file. Without them the file is still checked, but the settings
+		// flags can override are reported as unverified.
+		flags, flagsErr := k8s.ReadKubeletFlags(root)
+		if kubeletConfig == "" {
+			kubeletConfig = flags["config"]
+		}
+		if kubeletConfig == "" {
+			kubeletConfig = k8s.KubeletConfigPath
+		}
+		var config *k8s.KubeletConfig
+		configPath, err := k8s.HostPath(root, kubeletConfig)
+		if err == nil {
+			config, err = k8s.ReadKubeletConfigFile(configPath)
+		}
+		switch {
+		case err == nil:
+			if flagsErr == nil {
+				flagsErr = config.ApplyFlags(flags)
+			}
+			if flagsErr != nil {
+				logger.Error("Failed to read kubelet flags", "error", flagsErr)
+				snap.Errors = append(snap.Errors, k8s.CollectError{
+					Resource: k8s.ResourceKubeletConfigs.String(),
+					Reason:   k8s.ReasonError,
+					Message:  fmt.Sprintf("kubelet flags could not be read, so settings they can override (anonymous auth, authorization mode, read-only port, kernel defaults) are unverified: %v", flagsErr),
+				})
+			}
+			snap.KubeletConfigs = map[string]k8s.KubeletConfig{nodeName: *config}
+			snap.Collected = []string{k8s.ResourceKubeletConfigs.String()}
+		case errors.Is(err, fs.ErrNotExist):
+			snap.Errors = append(snap.Errors, k8s.CollectError{
+				Resource: k8s.ResourceKubeletConfigs.String(),
+				Reason:   k8s.ReasonUnsupported,
+				Message:  fmt.Sprintf("kubelet configuration %s not found; pass --kubelet-config", kubeletConfig),
+			})
+		default:
+			logger.Error("Failed to read kubelet configuration", "error", err)
+			snap.Errors = append(snap.Errors, k8s.CollectError{
+				Resource: k8s.ResourceKubeletConfigs.String(),
+				Reason:   k8s.ReasonError,
+				Message:  err.Error(),
+			})
+		}
+
+		result, err := policy.NewNodeAuditEngine(root, nodeName).Evaluate(context.Background(), snap)
+		if err != nil {
+			fmt.Println(ui.Error("Node audit failed: " + err.Error()))
+			os.Exit(1)
+		}
+		logger.Log.Info("Node audit completed", "node", nodeName, "issues_found", result.Stats.TotalIssues, "scanner_errors", len(result.Errors))
+
+		writeResult(result, viper.GetString("output"), "node-audit-"+nodeName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nodeAuditCmd)
+
+	nodeAuditCmd.Flags().String("root", "/", "Mount point of the node's root filesystem, e.g. /host in a DaemonSet")
+	nodeAuditCmd.Flags().String("node-name", "", "Node name used in findings (default is $NODE_NAME or the hostname)")
+	nodeAuditCmd.Flags().String("cluster", "", "Cluster name recorded in the result, matching the cluster scan it will be merged with")
+	nodeAuditCmd.Flags().String("kubelet-config", "", "Path of the kubelet configuration file on the node (default is the kubelet's --config flag, then "+k8s.KubeletConfigPath+")")
+}
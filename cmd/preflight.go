@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"github.com/ismailtsdln/HardenaK8s/internal/policy"
+	"github.com/ismailtsdln/HardenaK8s/internal/report"
+	"github.com/ismailtsdln/HardenaK8s/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// preflightCmd represents the preflight command
+var preflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check that the current identity can run a scan",
+	Long: `The preflight command asks the API server whether the current identity
+can read every resource the enabled scanners need, prints a permission matrix and
+can emit the minimal read-only ClusterRole required to run HardenaK8s.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace, _ := cmd.Flags().GetString("namespace")
+		allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+		roleFile, _ := cmd.Flags().GetString("emit-clusterrole")
+		includeNodes, _ := cmd.Flags().GetBool("include-nodes")
+		includeReliability, _ := cmd.Flags().GetBool("include-reliability")
+		excludeSecrets, _ := cmd.Flags().GetBool("exclude-secrets")
+
+		if allNamespaces {
+			namespace = ""
+		}
+
+		fmt.Println(ui.StyleHeader.Render("Running Preflight Checks..."))
+
+		client, err := newClient(cmd)
+		if err != nil {
+			fmt.Println(ui.Error("Failed to initialize Kubernetes client: " + err.Error()))
+			os.Exit(1)
+		}
+
+		resources := policy.NewEngine(client, policy.Options{
+			IncludeNodes:       includeNodes,
+			IncludeReliability: includeReliability,
+			ExcludeSecrets:     excludeSecrets,
+		}).Resources()
+
+		if roleFile != "" {
+			data, err := k8s.ReadOnlyClusterRoleYAML(resources)
+			if err != nil {
+				fmt.Println(ui.Error("Failed to render ClusterRole: " + err.Error()))
+				os.Exit(1)
+			}
+			if err := report.SaveToFile(data, roleFile); err != nil {
+				fmt.Println(ui.Error("Failed to save ClusterRole: " + err.Error()))
+				os.Exit(1)
+			}
+			fmt.Println(ui.Success("Read-only ClusterRole saved to " + roleFile))
+			if slices.Contains(resources, k8s.ResourceSecrets) {
+				fmt.Println(ui.Warning("The ClusterRole can read Secret values in every namespace; pass --exclude-secrets to skip the checks that need it."))
+			}
+		}
+
+		missing, err := runPreflight(context.Background(), client, resources, namespace, true)
+		if err != nil {
+			fmt.Println(ui.Error("Preflight failed: " + err.Error()))
+			os.Exit(1)
+		}
+		if missing > 0 {
+			fmt.Println(ui.Info("Run 'hardena preflight --emit-clusterrole hardena-role.yaml' to generate the required permissions."))
+			os.Exit(1)
+		}
+	},
+}
+
+// runPreflight checks access to resources and prints the permission matrix.
+// When verbose is false only missing permissions are printed. It returns the
+// number of missing permissions.
+func runPreflight(ctx context.Context, client *k8s.Client, resources []k8s.Resource, namespace string, verbose bool) (int, error) {
+	checks, err := client.CheckAccess(ctx, resources, namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	missing := 0
+	for _, check := range checks {
+		if !check.Allowed {
+			missing++
+		}
+	}
+
+	if verbose || missing > 0 {
+		fmt.Println(ui.StyleHeader.Render("Permission Matrix"))
+		fmt.Printf("%-32s %-6s %-16s %s\n", "RESOURCE", "VERB", "SCOPE", "ALLOWED")
+		for _, check := range checks {
+			if !verbose && check.Allowed {
+				continue
+			}
+
+			scope := check.Namespace
+			if scope == "" {
+				scope = "cluster"
+			}
+			status := ui.StyleSuccess.Render("yes")
+			if !check.Allowed {
+				status = ui.StyleError.Render("no")
+				if check.Reason != "" {
+					status += " (" + check.Reason + ")"
+				}
+			}
+			fmt.Printf("%-32s %-6s %-16s %s\n", check.Resource.String(), check.Verb, scope, status)
+		}
+	}
+
+	if missing == 0 {
+		fmt.Println(ui.Success("All required permissions are granted."))
+	} else {
+		fmt.Println(ui.Warning(fmt.Sprintf("%d required permissions are missing; affected scanners will report incomplete results.", missing)))
+	}
+	return missing, nil
+}
+
+func init() {
+	rootCmd.AddCommand(preflightCmd)
+
+	preflightCmd.Flags().String("namespace", "", "Check permissions for a specific namespace")
+	preflightCmd.Flags().Bool("all-namespaces", true, "Check permissions across all namespaces")
+	preflightCmd.Flags().Bool("include-nodes", false, "Include the permissions needed by 'scan --include-nodes'")
+	preflightCmd.Flags().Bool("include-reliability", false, "Include the permissions needed by 'scan --include-reliability'")
+	preflightCmd.Flags().Bool("exclude-secrets", false, "Leave out read access to Secrets, matching 'scan --exclude-secrets'")
+	preflightCmd.Flags().String("emit-clusterrole", "", "Write the minimal read-only ClusterRole to this file")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ismailtsdln/HardenaK8s/internal/policy"
 	"github.com/ismailtsdln/HardenaK8s/internal/report"
@@ -20,12 +21,12 @@ var reportCmd = &cobra.Command{
 	Long: `The report command processes the results of a previous scan 
 and generates a report in the specified format (JSON, YAML, or HTML).`,
 	Run: func(cmd *cobra.Command, args []string) {
-		inputFile, _ := cmd.Flags().GetString("input")
+		inputFiles, _ := cmd.Flags().GetStringSlice("input")
 		outputDir, _ := cmd.Flags().GetString("output-dir")
 		outputFormat := viper.GetString("output")
 
 		fmt.Println(ui.StyleHeader.Render("Generating Security Report..."))
-		fmt.Println(ui.Info(fmt.Sprintf("Input:  %s", inputFile)))
+		fmt.Println(ui.Info(fmt.Sprintf("Input:  %s", strings.Join(inputFiles, ", "))))
 		fmt.Println(ui.Info(fmt.Sprintf("Format: %s", outputFormat)))
 
 		// Create output directory if it doesn't exist
@@ -34,22 +35,35 @@ and generates a report in the specified format (JSON, YAML, or HTML).`,
 			return
 		}
 
-		// Read input results
-		data, err := os.ReadFile(inputFile)
-		if err != nil {
-			fmt.Println(ui.Error("Failed to read input file: " + err.Error()))
-			return
+		// Read input results, merging them when several clusters are given
+		var results []*policy.Result
+		for _, inputFile := range inputFiles {
+			data, err := os.ReadFile(inputFile)
+			if err != nil {
+				fmt.Println(ui.Error("Failed to read input file: " + err.Error()))
+				return
+			}
+
+			var result policy.Result
+			if err := json.Unmarshal(data, &result); err != nil {
+				fmt.Println(ui.Error("Failed to parse scan results: " + err.Error()))
+				return
+			}
+
+			// Safety check for unmarshaled data
+			if result.Stats.SeverityCount == nil {
+				result.Stats.SeverityCount = make(map[policy.Severity]int)
+			}
+			results = append(results, &result)
 		}
 
-		var result policy.Result
-		if err := json.Unmarshal(data, &result); err != nil {
-			fmt.Println(ui.Error("Failed to parse scan results: " + err.Error()))
+		if len(results) == 0 {
+			fmt.Println(ui.Error("No input files given"))
 			return
 		}
-
-		// Safety check for unmarshaled data
-		if result.Stats.SeverityCount == nil {
-			result.Stats.SeverityCount = make(map[policy.Severity]int)
+		result := results[0]
+		if len(results) > 1 {
+			result = policy.Merge(results...)
 		}
 
 		// Format report
@@ -60,7 +74,7 @@ and generates a report in the specified format (JSON, YAML, or HTML).`,
 			outputFormat = "json"
 		}
 
-		outputData, err := formatter.Format(&result)
+		outputData, err := formatter.Format(result)
 		if err != nil {
 			fmt.Println(ui.Error("Failed to format report: " + err.Error()))
 			return
@@ -80,6 +94,6 @@ and generates a report in the specified format (JSON, YAML, or HTML).`,
 func init() {
 	rootCmd.AddCommand(reportCmd)
 
-	reportCmd.Flags().String("input", "scan-results.json", "Input file with scan results")
+	reportCmd.Flags().StringSlice("input", []string{"scan-results.json"}, "Input files with scan results; several files are merged into a fleet report")
 	reportCmd.Flags().String("output-dir", "./reports", "Directory to save the generated report")
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
 	"github.com/ismailtsdln/HardenaK8s/internal/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,6 +37,42 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.hardena.yaml)")
 	rootCmd.PersistentFlags().StringP("output", "o", "text", "Output format (text, json, yaml, html)")
+	rootCmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file (default is $KUBECONFIG or $HOME/.kube/config)")
+	rootCmd.PersistentFlags().String("context", "", "Kubeconfig context to use (default is the current context)")
+	rootCmd.PersistentFlags().String("as", "", "Username to impersonate for API requests")
+	rootCmd.PersistentFlags().StringArray("as-group", nil, "Group to impersonate for API requests, can be repeated")
+	rootCmd.PersistentFlags().Duration("request-timeout", 0, "Timeout for a single API request (0 means no timeout)")
+	rootCmd.PersistentFlags().Float32("qps", 50, "Maximum queries per second sent to the API server")
+	rootCmd.PersistentFlags().Int("burst", 100, "Maximum burst of requests sent to the API server")
+	rootCmd.PersistentFlags().Int64("page-size", k8s.DefaultPageSize, "Number of objects fetched per List call (0 disables pagination)")
+}
+
+// newClient builds a Kubernetes client from the connection flags shared by all commands
+func newClient(cmd *cobra.Command) (*k8s.Client, error) {
+	contextName, _ := cmd.Flags().GetString("context")
+	return newClientForContext(cmd, contextName)
+}
+
+// newClientForContext is like newClient but targets the given kubeconfig context
+func newClientForContext(cmd *cobra.Command, contextName string) (*k8s.Client, error) {
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	as, _ := cmd.Flags().GetString("as")
+	asGroups, _ := cmd.Flags().GetStringArray("as-group")
+	timeout, _ := cmd.Flags().GetDuration("request-timeout")
+	qps, _ := cmd.Flags().GetFloat32("qps")
+	burst, _ := cmd.Flags().GetInt("burst")
+	pageSize, _ := cmd.Flags().GetInt64("page-size")
+
+	return k8s.NewClient(k8s.Options{
+		Kubeconfig:        kubeconfig,
+		Context:           contextName,
+		Impersonate:       as,
+		ImpersonateGroups: asGroups,
+		RequestTimeout:    timeout,
+		QPS:               qps,
+		Burst:             burst,
+		PageSize:          pageSize,
+	})
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -4,14 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
 	"github.com/ismailtsdln/HardenaK8s/internal/logger"
 	"github.com/ismailtsdln/HardenaK8s/internal/policy"
 	"github.com/ismailtsdln/HardenaK8s/internal/report"
 	"github.com/ismailtsdln/HardenaK8s/internal/ui"
+	"github.com/ismailtsdln/HardenaK8s/internal/vuln"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/util/version"
 )
 
 // scanCmd represents the scan command
@@ -24,6 +29,17 @@ RBAC issues, and more.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		namespace, _ := cmd.Flags().GetString("namespace")
 		allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+		workers, _ := cmd.Flags().GetInt("workers")
+		snapshotFile, _ := cmd.Flags().GetString("snapshot")
+		failOnPartial, _ := cmd.Flags().GetBool("fail-on-partial")
+		allContexts, _ := cmd.Flags().GetBool("all-contexts")
+		contexts, _ := cmd.Flags().GetStringSlice("contexts")
+		vulnReports, _ := cmd.Flags().GetString("vuln-report")
+		includeNodes, _ := cmd.Flags().GetBool("include-nodes")
+		includeReliability, _ := cmd.Flags().GetBool("include-reliability")
+		excludeSecrets, _ := cmd.Flags().GetBool("exclude-secrets")
+		targetVersion, _ := cmd.Flags().GetString("target-version")
+		manifestPath, _ := cmd.Flags().GetString("manifests")
 
 		if allNamespaces {
 			namespace = ""
@@ -31,68 +47,242 @@ RBAC issues, and more.`,
 
 		fmt.Println(ui.StyleHeader.Render("Starting Security Scan..."))
 
-		client, err := k8s.NewClient()
-		if err != nil {
-			fmt.Println(ui.Error("Failed to initialize Kubernetes client: " + err.Error()))
-			os.Exit(1)
+		ctx := context.Background()
+		opts := policy.Options{
+			Workers:            workers,
+			AllowedRegistries:  viper.GetStringSlice("images.allowed-registries"),
+			IncludeNodes:       includeNodes,
+			IncludeReliability: includeReliability,
+			ExcludeSecrets:     excludeSecrets,
+			TargetVersion:      targetVersion,
 		}
 
-		ctx := context.Background()
-		fmt.Println(ui.Info("Checking cluster connectivity..."))
-		if err := client.CheckConnectivity(ctx); err != nil {
-			fmt.Println(ui.Error("Could not connect to Kubernetes cluster: " + err.Error()))
+		if err := viper.UnmarshalKey("tenancy", &opts.Tenancy); err != nil {
+			fmt.Println(ui.Error("Invalid tenancy configuration: " + err.Error()))
 			os.Exit(1)
 		}
-		fmt.Println(ui.Success("Connected to cluster."))
-
-		fmt.Println(ui.Info("Auditing resources..."))
-		engine := policy.NewEngine(client)
-		result, err := engine.Run(ctx, namespace)
-		if err != nil {
-			fmt.Println(ui.Error("Scan failed: " + err.Error()))
+		if err := opts.Tenancy.Validate(); err != nil {
+			fmt.Println(ui.Error("Invalid tenancy configuration: " + err.Error()))
 			os.Exit(1)
 		}
 
-		logger.Log.Info("Scan completed", "issues_found", result.Stats.TotalIssues)
+		if targetVersion != "" {
+			if _, err := version.ParseGeneric(targetVersion); err != nil {
+				fmt.Println(ui.Error("Invalid --target-version: " + err.Error()))
+				os.Exit(1)
+			}
+		}
 
-		outputFormat := viper.GetString("output")
+		if manifestPath != "" {
+			manifests, err := k8s.LoadManifests(manifestPath)
+			if err != nil {
+				fmt.Println(ui.Error("Failed to load manifests: " + err.Error()))
+				os.Exit(1)
+			}
+			fmt.Println(ui.Info(fmt.Sprintf("Loaded %d manifests from %s", len(manifests), manifestPath)))
+			opts.Manifests = manifests
+		}
 
-		if outputFormat == "text" {
-			renderTable(result)
-		} else {
-			formatter, err := report.GetFormatter(outputFormat)
+		if vulnReports != "" {
+			db, err := vuln.LoadDir(vulnReports)
 			if err != nil {
-				fmt.Println(ui.Warning("Invalid output format, defaulting to JSON"))
-				formatter = &report.JSONFormatter{}
-				outputFormat = "json"
+				fmt.Println(ui.Error("Failed to load vulnerability reports: " + err.Error()))
+				os.Exit(1)
 			}
+			fmt.Println(ui.Info(fmt.Sprintf("Loaded %d vulnerability reports from %s", len(db.Reports), vulnReports)))
+			opts.Vulnerabilities = db
+		}
 
-			data, err := formatter.Format(result)
+		if allContexts {
+			kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+			var err error
+			contexts, err = k8s.Contexts(kubeconfig)
 			if err != nil {
-				fmt.Println(ui.Error("Failed to format report: " + err.Error()))
-				return
+				fmt.Println(ui.Error("Failed to list kubeconfig contexts: " + err.Error()))
+				os.Exit(1)
 			}
+		}
 
-			outputFile := fmt.Sprintf("scan-results.%s", outputFormat)
-			err = report.SaveToFile(data, outputFile)
+		var results []*policy.Result
+		switch {
+		case snapshotFile != "":
+			fmt.Println(ui.Info("Loading snapshot " + snapshotFile + "..."))
+			snap, err := k8s.ReadArchive(snapshotFile)
 			if err != nil {
-				fmt.Println(ui.Error("Failed to save report: " + err.Error()))
-			} else {
-				fmt.Println(ui.Success("Report saved to " + outputFile))
+				fmt.Println(ui.Error("Failed to load snapshot: " + err.Error()))
+				os.Exit(1)
+			}
+			fmt.Println(ui.Info(fmt.Sprintf("Snapshot captured at %s", snap.CapturedAt.Format(time.RFC3339))))
+
+			fmt.Println(ui.Info("Auditing resources..."))
+			result, err := policy.NewEngine(nil, opts).Evaluate(ctx, snap)
+			if err != nil {
+				fmt.Println(ui.Error("Scan failed: " + err.Error()))
+				os.Exit(1)
+			}
+			results = append(results, result)
+
+		case len(contexts) > 0:
+			// One unreachable cluster should not hide the others, so failures
+			// are recorded in that cluster's result instead of aborting
+			for _, contextName := range contexts {
+				fmt.Println(ui.StyleHeader.Render("Cluster: " + contextName))
+				result, err := scanLive(ctx, cmd, contextName, namespace, opts)
+				if err != nil {
+					fmt.Println(ui.Error("Scan failed: " + err.Error()))
+					result = &policy.Result{
+						Cluster: contextName,
+						Issues:  []policy.Issue{},
+						Stats:   policy.Stats{SeverityCount: map[policy.Severity]int{}},
+						Errors: []policy.ScanError{{
+							Scanner:    "cluster",
+							Reason:     k8s.ErrorReason(err),
+							Namespaces: []string{"*"},
+							Message:    err.Error(),
+						}},
+					}
+				}
+				results = append(results, result)
 			}
+
+		default:
+			contextName, _ := cmd.Flags().GetString("context")
+			result, err := scanLive(ctx, cmd, contextName, namespace, opts)
+			if err != nil {
+				fmt.Println(ui.Error(err.Error()))
+				os.Exit(1)
+			}
+			results = append(results, result)
+		}
+
+		for _, result := range results {
+			logger.Log.Info("Scan completed", "cluster", result.Cluster, "issues_found", result.Stats.TotalIssues, "scanner_errors", len(result.Errors))
+		}
+
+		result := results[0]
+		if len(results) > 1 {
+			result = policy.Merge(results...)
+		}
+		writeResult(result, viper.GetString("output"), "scan-results")
+
+		if failOnPartial && result.Partial() {
+			fmt.Println(ui.Error(fmt.Sprintf("Scan is incomplete: %d scanner errors", len(result.Errors))))
+			os.Exit(1)
 		}
 	},
 }
 
+// scanLive connects to the cluster behind contextName and runs a full scan
+func scanLive(ctx context.Context, cmd *cobra.Command, contextName, namespace string, opts policy.Options) (*policy.Result, error) {
+	skipPreflight, _ := cmd.Flags().GetBool("skip-preflight")
+
+	client, err := newClientForContext(cmd, contextName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Kubernetes client: %w", err)
+	}
+
+	fmt.Println(ui.Info("Checking cluster connectivity..."))
+	if err := client.CheckConnectivity(ctx); err != nil {
+		return nil, fmt.Errorf("could not connect to Kubernetes cluster %s: %w", client.Cluster, err)
+	}
+	fmt.Println(ui.Success("Connected to cluster " + client.Cluster + "."))
+
+	engine := policy.NewEngine(client, opts)
+	if !skipPreflight {
+		fmt.Println(ui.Info("Checking permissions..."))
+		if _, err := runPreflight(ctx, client, engine.Resources(), namespace, false); err != nil {
+			fmt.Println(ui.Warning("Could not verify permissions: " + err.Error()))
+		}
+	}
+
+	fmt.Println(ui.Info("Auditing resources..."))
+	result, err := engine.Run(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("scan of %s failed: %w", client.Cluster, err)
+	}
+	return result, nil
+}
+
+// writeResult renders a result to the terminal or saves it as <baseName>.<format>
+func writeResult(result *policy.Result, outputFormat, baseName string) {
+	if outputFormat == "text" {
+		renderTable(result)
+		return
+	}
+
+	formatter, err := report.GetFormatter(outputFormat)
+	if err != nil {
+		fmt.Println(ui.Warning("Invalid output format, defaulting to JSON"))
+		formatter = &report.JSONFormatter{}
+		outputFormat = "json"
+	}
+
+	data, err := formatter.Format(result)
+	if err != nil {
+		fmt.Println(ui.Error("Failed to format report: " + err.Error()))
+		return
+	}
+
+	outputFile := fmt.Sprintf("%s.%s", baseName, outputFormat)
+	err = report.SaveToFile(data, outputFile)
+	if err != nil {
+		fmt.Println(ui.Error("Failed to save report: " + err.Error()))
+	} else {
+		fmt.Println(ui.Success("Report saved to " + outputFile))
+	}
+}
+
 func renderTable(result *policy.Result) {
-	if len(result.Issues) == 0 {
+	switch {
+	case len(result.Issues) > 0:
+		renderIssues(result.Issues)
+	case result.Partial():
+		fmt.Println("\n" + ui.Warning("No security issues found in the resources that could be inspected."))
+	case result.Stats.ResourcesScanned == 0:
+		fmt.Println("\n" + ui.Warning("No resources were inspected. Check the namespace and your permissions."))
+	default:
 		fmt.Println("\n" + ui.Success("No security issues found! Your cluster is hardened. 🛡️"))
-		return
 	}
 
+	if result.Partial() {
+		fmt.Println(ui.StyleHeader.Render("Incomplete Coverage"))
+		for _, scanErr := range result.Errors {
+			scanner := scanErr.Scanner
+			if scanErr.Cluster != "" {
+				scanner = scanErr.Cluster + "/" + scanner
+			}
+			fmt.Println(ui.Warning(fmt.Sprintf("%s [%s] namespaces=%s: %s",
+				scanner, scanErr.Reason, strings.Join(scanErr.Namespaces, ","), scanErr.Message)))
+		}
+	}
+
+	if result.Fleet != nil {
+		renderFleet(result.Fleet)
+	}
+
+	fmt.Println(ui.StyleHeader.Render("Scan Statistics"))
+	fmt.Printf("Total Issues:    %d\n", result.Stats.TotalIssues)
+	for sev, count := range result.Stats.SeverityCount {
+		fmt.Printf("%-15s %d\n", sev+":", count)
+	}
+	fmt.Printf("Resources:       %d\n", result.Stats.ResourcesScanned)
+	for _, kind := range sortedKeys(result.Stats.ResourcesByKind) {
+		fmt.Printf("  %-13s %d\n", kind+":", result.Stats.ResourcesByKind[kind])
+	}
+	fmt.Printf("Checks:          %d passed, %d failed\n", result.Stats.ChecksPassed, result.Stats.ChecksFailed)
+
+	if len(result.Stats.Scanners) > 0 {
+		fmt.Println(ui.StyleHeader.Render("Scanner Timings"))
+		for _, s := range result.Stats.Scanners {
+			fmt.Printf("%-15s %-12s %d objects, %d checks, %d issues\n", s.Name+":", s.Duration.Round(time.Millisecond), s.Objects, s.Checks, s.Issues)
+		}
+	}
+}
+
+func renderIssues(issues []policy.Issue) {
 	fmt.Println(ui.StyleHeader.Render("\nSecurity Findings Summary"))
 
-	for _, issue := range result.Issues {
+	for _, issue := range issues {
 		var sevStyle = ui.StyleInfo
 		switch issue.Severity {
 		case policy.SeverityCritical:
@@ -104,21 +294,71 @@ func renderTable(result *policy.Result) {
 		}
 
 		fmt.Printf("[%s] %s\n", sevStyle.Render(string(issue.Severity)), ui.StyleHeader.Render(issue.Title))
+		if issue.Cluster != "" {
+			fmt.Printf("   Cluster:  %s\n", issue.Cluster)
+		}
 		fmt.Printf("   Resource: %s/%s\n", issue.Namespace, issue.Resource)
+		if issue.Container != "" {
+			if issue.ContainerType != "" && issue.ContainerType != policy.ContainerTypeRegular {
+				fmt.Printf("   Container: %s (%s)\n", issue.Container, issue.ContainerType)
+			} else {
+				fmt.Printf("   Container: %s\n", issue.Container)
+			}
+		}
 		fmt.Printf("   Details:  %s\n", issue.Description)
 		fmt.Printf("   Fix:      %s\n\n", ui.StyleSuccess.Render(issue.Remediation))
 	}
+}
 
-	fmt.Println(ui.StyleHeader.Render("Scan Statistics"))
-	fmt.Printf("Total Issues:    %d\n", result.Stats.TotalIssues)
-	for sev, count := range result.Stats.SeverityCount {
-		fmt.Printf("%-15s %d\n", sev+":", count)
+func renderFleet(fleet *policy.FleetSummary) {
+	fmt.Println(ui.StyleHeader.Render("Fleet Overview"))
+	fmt.Printf("%-30s %6s %9s %5s %5s %7s %5s\n", "CLUSTER", "SCORE", "CRITICAL", "HIGH", "MED", "LOW", "RISK")
+	for _, c := range fleet.Clusters {
+		name := c.Cluster
+		if c.Partial {
+			name += " (partial)"
+		}
+		fmt.Printf("%-30s %5d%% %9d %5d %5d %7d %5d\n", name, c.Score,
+			c.SeverityCount[policy.SeverityCritical], c.SeverityCount[policy.SeverityHigh],
+			c.SeverityCount[policy.SeverityMedium], c.SeverityCount[policy.SeverityLow], c.Risk)
+	}
+
+	if len(fleet.CommonFindings) > 0 {
+		fmt.Println(ui.StyleHeader.Render("Findings Shared Across Clusters"))
+		for _, f := range fleet.CommonFindings {
+			fmt.Printf("%s %s [%s] on %s\n", f.ID, f.Title, f.Severity, strings.Join(f.Clusters, ", "))
+		}
 	}
 }
 
+// sortedKeys returns the keys of a count map in a stable order
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func init() {
 	rootCmd.AddCommand(scanCmd)
 
 	scanCmd.Flags().String("namespace", "", "Scan a specific namespace")
 	scanCmd.Flags().Bool("all-namespaces", true, "Scan all namespaces")
+	scanCmd.Flags().Int("workers", policy.DefaultWorkers, "Number of scanners to run concurrently")
+	scanCmd.Flags().StringSlice("allowed-registries", nil, "Trusted image registries or registry/path prefixes (config: images.allowed-registries)")
+	scanCmd.Flags().Bool("all-contexts", false, "Scan every context in the kubeconfig")
+	scanCmd.Flags().StringSlice("contexts", nil, "Comma-separated list of kubeconfig contexts to scan")
+	scanCmd.Flags().Bool("skip-preflight", false, "Do not check permissions before scanning")
+	scanCmd.Flags().Bool("fail-on-partial", false, "Exit with an error when any scanner could not complete")
+	scanCmd.Flags().String("vuln-report", "", "Directory of Trivy, Grype or CycloneDX JSON reports to match against running images")
+	scanCmd.Flags().Bool("include-nodes", false, "Audit node versions and kubelet configuration (needs get on nodes/proxy)")
+	scanCmd.Flags().Bool("include-reliability", false, "Also check probes, replica counts and PodDisruptionBudgets")
+	scanCmd.Flags().Bool("exclude-secrets", false, "Do not read Secrets, skipping the checks that need them, so no access to secret values is needed")
+	scanCmd.Flags().String("target-version", "", "Kubernetes version to check deprecated APIs against (default is the server version)")
+	scanCmd.Flags().String("manifests", "", "YAML or JSON manifest file or directory to check for deprecated APIs")
+	scanCmd.Flags().String("snapshot", "", "Scan an archive created by 'hardena snapshot' instead of the live cluster")
+
+	cobra.CheckErr(viper.BindPFlag("images.allowed-registries", scanCmd.Flags().Lookup("allowed-registries")))
 }
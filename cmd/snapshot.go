@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"github.com/ismailtsdln/HardenaK8s/internal/logger"
+	"github.com/ismailtsdln/HardenaK8s/internal/policy"
+	"github.com/ismailtsdln/HardenaK8s/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Capture cluster state for offline audits",
+	Long: `The snapshot command lists every resource the scanners read and stores 
+them in a compressed archive. Secret values are redacted before they are written.
+Run 'hardena scan --snapshot <file>' to audit the archive later without cluster access.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace, _ := cmd.Flags().GetString("namespace")
+		allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+		workers, _ := cmd.Flags().GetInt("workers")
+		outFile, _ := cmd.Flags().GetString("out")
+		includeNodes, _ := cmd.Flags().GetBool("include-nodes")
+
+		if allNamespaces {
+			namespace = ""
+		}
+
+		fmt.Println(ui.StyleHeader.Render("Capturing Cluster Snapshot..."))
+
+		client, err := newClient(cmd)
+		if err != nil {
+			fmt.Println(ui.Error("Failed to initialize Kubernetes client: " + err.Error()))
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		fmt.Println(ui.Info("Checking cluster connectivity..."))
+		if err := client.CheckConnectivity(ctx); err != nil {
+			fmt.Println(ui.Error("Could not connect to Kubernetes cluster: " + err.Error()))
+			os.Exit(1)
+		}
+		fmt.Println(ui.Success("Connected to cluster."))
+
+		resources := k8s.AllResources
+		if includeNodes {
+			resources = append(resources[:len(resources):len(resources)], k8s.ResourceKubeletConfigs)
+		}
+		snap, err := k8s.Collect(ctx, client, namespace, resources, workers)
+		if err != nil {
+			logger.Error("Snapshot is incomplete", "error", err)
+			fmt.Println(ui.Warning("Some resources could not be captured: " + err.Error()))
+		}
+
+		if err := k8s.WriteArchive(outFile, snap); err != nil {
+			fmt.Println(ui.Error("Failed to write snapshot: " + err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Println(ui.Success(fmt.Sprintf("Captured %d resource types to %s", len(snap.Collected), outFile)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	snapshotCmd.Flags().String("out", "cluster.tar.gz", "Archive file to write")
+	snapshotCmd.Flags().String("namespace", "", "Capture a specific namespace")
+	snapshotCmd.Flags().Bool("all-namespaces", true, "Capture all namespaces")
+	snapshotCmd.Flags().Int("workers", policy.DefaultWorkers, "Number of resource types listed concurrently")
+	snapshotCmd.Flags().Bool("include-nodes", false, "Also capture each kubelet's configuration through nodes/proxy")
+}
@@ -0,0 +1,124 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ReadOnlyClusterRoleName is the name used for the generated ClusterRole
+const ReadOnlyClusterRoleName = "hardena-readonly"
+
+// AccessCheck is the outcome of a permission check for one resource and verb
+type AccessCheck struct {
+	Resource  Resource
+	Verb      string
+	Namespace string
+	Allowed   bool
+	Reason    string
+}
+
+// requiredVerb returns the verb Collect uses to read a resource
+func requiredVerb(r Resource, namespace string) string {
+	if r == ResourceNamespaces && namespace != "" {
+		return "get"
+	}
+	if strings.Contains(r.Resource, "/") {
+		// Subresources are read object by object
+		return "get"
+	}
+	return "list"
+}
+
+// CheckAccess asks the API server, via SelfSubjectAccessReview, whether the
+// current identity can read each resource in the given namespace scope
+func (c *Client) CheckAccess(ctx context.Context, resources []Resource, namespace string) ([]AccessCheck, error) {
+	var checks []AccessCheck
+	for _, r := range resources {
+		resource, subresource, _ := strings.Cut(r.Resource, "/")
+		attrs := &authorizationv1.ResourceAttributes{
+			Verb:        requiredVerb(r, namespace),
+			Group:       r.Group,
+			Version:     r.Version,
+			Resource:    resource,
+			Subresource: subresource,
+		}
+		if r.Namespaced {
+			attrs.Namespace = namespace
+		} else if r == ResourceNamespaces && namespace != "" {
+			attrs.Name = namespace
+		}
+
+		review, err := c.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attrs},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("access review for %s failed: %w", r, err)
+		}
+
+		checks = append(checks, AccessCheck{
+			Resource:  r,
+			Verb:      attrs.Verb,
+			Namespace: attrs.Namespace,
+			Allowed:   review.Status.Allowed,
+			Reason:    review.Status.Reason,
+		})
+	}
+	return checks, nil
+}
+
+// ReadOnlyClusterRole builds the minimal ClusterRole needed to collect the
+// given resources, grouping them into one rule per API group
+func ReadOnlyClusterRole(resources []Resource) *rbacv1.ClusterRole {
+	byGroup := map[string][]string{}
+	for _, r := range resources {
+		byGroup[r.Group] = append(byGroup[r.Group], r.Resource)
+	}
+
+	groups := make([]string, 0, len(byGroup))
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	role := &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{Name: ReadOnlyClusterRoleName},
+	}
+	for _, group := range groups {
+		names := byGroup[group]
+		sort.Strings(names)
+		role.Rules = append(role.Rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: names,
+			Verbs:     []string{"get", "list"},
+		})
+	}
+	return role
+}
+
+// secretsWarning heads a rendered ClusterRole that can read Secrets
+const secretsWarning = `# WARNING: this ClusterRole grants get and list on Secrets in every namespace,
+# which includes their values. Run 'hardena preflight --exclude-secrets' to
+# skip the checks that need it.
+`
+
+// ReadOnlyClusterRoleYAML renders ReadOnlyClusterRole as a manifest, with a
+// warning when the role can read Secrets
+func ReadOnlyClusterRoleYAML(resources []Resource) ([]byte, error) {
+	data, err := yaml.Marshal(ReadOnlyClusterRole(resources))
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(resources, ResourceSecrets) {
+		data = append([]byte(secretsWarning), data...)
+	}
+	return data, nil
+}
@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheckAccessReportsDeniedResources(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Resource != "secrets"
+		return true, review, nil
+	})
+
+	client := &Client{Clientset: clientset}
+	checks, err := client.CheckAccess(context.Background(), []Resource{ResourcePods, ResourceSecrets, ResourceNamespaces}, "shop")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 checks, got %d", len(checks))
+	}
+	if !checks[0].Allowed || checks[0].Verb != "list" || checks[0].Namespace != "shop" {
+		t.Errorf("unexpected pods check: %+v", checks[0])
+	}
+	if checks[1].Allowed {
+		t.Errorf("expected secrets to be denied: %+v", checks[1])
+	}
+	if checks[2].Verb != "get" || checks[2].Namespace != "" {
+		t.Errorf("expected a cluster-scoped get on the scanned namespace, got %+v", checks[2])
+	}
+}
+
+func TestReadOnlyClusterRoleGroupsRules(t *testing.T) {
+	data, err := ReadOnlyClusterRoleYAML([]Resource{ResourcePods, ResourceDeployments, ResourceNamespaces, ResourceReplicaSets})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	manifest := string(data)
+	for _, want := range []string{"kind: ClusterRole", "name: " + ReadOnlyClusterRoleName, "- deployments\n  - replicasets", "- namespaces\n  - pods"} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("expected manifest to contain %q:\n%s", want, manifest)
+		}
+	}
+	if strings.Contains(manifest, "create") || strings.Contains(manifest, "delete") {
+		t.Errorf("expected a read-only role:\n%s", manifest)
+	}
+	if strings.Contains(manifest, "WARNING") {
+		t.Errorf("expected no Secrets warning without Secrets:\n%s", manifest)
+	}
+
+	data, err = ReadOnlyClusterRoleYAML([]Resource{ResourcePods, ResourceSecrets})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# WARNING") {
+		t.Errorf("expected a role reading Secrets to carry a warning:\n%s", data)
+	}
+}
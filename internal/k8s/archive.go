@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ArchiveFormatVersion is bumped whenever the archive layout changes incompatibly
+const ArchiveFormatVersion = 1
+
+const (
+	archiveMetadataFile = "metadata.json"
+	archiveSnapshotFile = "snapshot.json"
+)
+
+// ArchiveMetadata describes a snapshot archive
+type ArchiveMetadata struct {
+	FormatVersion int       `json:"formatVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Cluster       string    `json:"cluster"`
+	Namespace     string    `json:"namespace"`
+	Collected     []string  `json:"collected"`
+}
+
+// WriteArchive stores a snapshot as a gzip-compressed tarball. Copies of
+// the Secrets are redacted again before writing so an archive never carries
+// secret values, even for snapshots that were not produced by Collect; the
+// snapshot itself is left unchanged.
+func WriteArchive(path string, snap *Snapshot) error {
+	secrets := make([]corev1.Secret, len(snap.Secrets))
+	for i := range snap.Secrets {
+		snap.Secrets[i].DeepCopyInto(&secrets[i])
+		redactSecret(&secrets[i])
+	}
+
+	metadata, err := json.MarshalIndent(ArchiveMetadata{
+		FormatVersion: ArchiveFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Cluster:       snap.Cluster,
+		Namespace:     snap.Namespace,
+		Collected:     snap.Collected,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive metadata: %w", err)
+	}
+
+	// The outer Secrets field takes precedence over the embedded one
+	objects, err := json.Marshal(struct {
+		*Snapshot
+		Secrets []corev1.Secret `json:"secrets,omitempty"`
+	}{snap, secrets})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{archiveMetadataFile, metadata},
+		{archiveSnapshotFile, objects},
+	} {
+		header := &tar.Header{
+			Name:    entry.name,
+			Mode:    0644,
+			Size:    int64(len(entry.data)),
+			ModTime: snap.CapturedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ReadArchive loads a snapshot previously written by WriteArchive
+func ReadArchive(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	var metadata *ArchiveMetadata
+	var snap *Snapshot
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		switch header.Name {
+		case archiveMetadataFile:
+			metadata = &ArchiveMetadata{}
+			if err := json.NewDecoder(tr).Decode(metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode archive metadata: %w", err)
+			}
+		case archiveSnapshotFile:
+			snap = &Snapshot{}
+			if err := json.NewDecoder(tr).Decode(snap); err != nil {
+				return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+			}
+		}
+	}
+
+	if metadata == nil || snap == nil {
+		return nil, fmt.Errorf("archive is missing %s or %s", archiveMetadataFile, archiveSnapshotFile)
+	}
+	if metadata.FormatVersion != ArchiveFormatVersion {
+		return nil, fmt.Errorf("unsupported archive format version %d (expected %d)", metadata.FormatVersion, ArchiveFormatVersion)
+	}
+
+	return snap, nil
+}
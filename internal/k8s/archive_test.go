@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestArchiveRoundTripRedactsSecrets(t *testing.T) {
+	snap := &Snapshot{
+		Namespace: "shop",
+		Collected: []string{"pods", "secrets"},
+		Pods: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		}},
+		Secrets: []corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "db",
+				Namespace:   "shop",
+				Annotations: map[string]string{LastAppliedAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`},
+			},
+			Data: map[string][]byte{"password": []byte("hunter2")},
+		}},
+	}
+
+	path := filepath.Join(t.TempDir(), "cluster.tar.gz")
+	if err := WriteArchive(path, snap); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+
+	loaded, err := ReadArchive(path)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	if loaded.Namespace != "shop" || !loaded.Has(ResourcePods) || len(loaded.Pods) != 1 {
+		t.Errorf("snapshot contents did not survive the round trip: %+v", loaded)
+	}
+
+	secret := loaded.Secrets[0]
+	value, ok := secret.Data["password"]
+	if !ok {
+		t.Error("expected secret keys to be preserved")
+	}
+	if len(value) != 0 {
+		t.Errorf("expected secret value to be redacted, got %q", value)
+	}
+	if _, ok := secret.Annotations[LastAppliedAnnotation]; ok {
+		t.Error("expected last-applied-configuration annotation to be dropped from secrets")
+	}
+
+	// The caller's snapshot keeps its values
+	if string(snap.Secrets[0].Data["password"]) != "hunter2" || snap.Secrets[0].Annotations[LastAppliedAnnotation] == "" {
+		t.Errorf("expected the written snapshot to be left unchanged, got %+v", snap.Secrets[0])
+	}
+}
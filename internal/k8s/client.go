@@ -3,41 +3,61 @@ package k8s
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
 )
 
+// DefaultPageSize is the number of objects requested per List call
+const DefaultPageSize int64 = 500
+
+// InClusterName is the cluster name reported when running inside a pod
+const InClusterName = "in-cluster"
+
+// Options configures how the Kubernetes client talks to the API server
+type Options struct {
+	// Kubeconfig is an explicit kubeconfig path. Empty uses KUBECONFIG or ~/.kube/config.
+	Kubeconfig string
+	// Context selects a kubeconfig context. Empty uses the current context.
+	Context string
+	// Impersonate and ImpersonateGroups set the user and groups to act as
+	Impersonate       string
+	ImpersonateGroups []string
+	// RequestTimeout bounds every API request. Zero means no timeout.
+	RequestTimeout time.Duration
+	// QPS is the client-side rate limit. Zero keeps the client-go default.
+	QPS float32
+	// Burst is the client-side burst allowance. Zero keeps the client-go default.
+	Burst int
+	// PageSize is the Limit used for paginated List calls. Zero disables pagination.
+	PageSize int64
+}
+
 // Client is a wrapper for the Kubernetes clientset
 type Client struct {
-	Clientset *kubernetes.Clientset
+	Clientset kubernetes.Interface
+	// Dynamic reads resources defined by CRDs, such as the Gateway API
+	Dynamic  dynamic.Interface
+	PageSize int64
+	// Cluster names the cluster the client talks to: the kubeconfig context
+	// or InClusterName
+	Cluster string
+	// ServerVersion is recorded by CheckConnectivity
+	ServerVersion *version.Info
 }
 
 // NewClient creates a new Kubernetes client
-func NewClient() (*Client, error) {
-	var config *rest.Config
-	var err error
-
-	// Try in-cluster config first
-	config, err = rest.InClusterConfig()
+func NewClient(opts Options) (*Client, error) {
+	config, cluster, err := restConfig(opts)
 	if err != nil {
-		// Fallback to kubeconfig
-		kubeconfig := os.Getenv("KUBECONFIG")
-		if kubeconfig == "" {
-			if home := homedir.HomeDir(); home != "" {
-				kubeconfig = filepath.Join(home, ".kube", "config")
-			}
-		}
-
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
-		}
+		return nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
@@ -45,27 +65,147 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
 	return &Client{
 		Clientset: clientset,
+		Dynamic:   dynamicClient,
+		PageSize:  opts.PageSize,
+		Cluster:   cluster,
 	}, nil
 }
 
+// restConfig resolves the REST config for opts, including impersonation,
+// timeout and rate limit settings
+func restConfig(opts Options) (*rest.Config, string, error) {
+	config, cluster, err := loadConfig(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if opts.Impersonate != "" || len(opts.ImpersonateGroups) > 0 {
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: opts.Impersonate,
+			Groups:   opts.ImpersonateGroups,
+		}
+	}
+	if opts.RequestTimeout > 0 {
+		config.Timeout = opts.RequestTimeout
+	}
+	if opts.QPS > 0 {
+		config.QPS = opts.QPS
+	}
+	if opts.Burst > 0 {
+		config.Burst = opts.Burst
+	}
+	return config, cluster, nil
+}
+
+// loadConfig resolves the REST config and cluster name for opts
+func loadConfig(opts Options) (*rest.Config, string, error) {
+	// Try in-cluster config first, unless a kubeconfig or context was requested
+	if opts.Kubeconfig == "" && opts.Context == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			return config, InClusterName, nil
+		}
+	}
+
+	// Fallback to kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules(opts.Kubeconfig),
+		&clientcmd.ConfigOverrides{CurrentContext: opts.Context},
+	)
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	cluster := opts.Context
+	if cluster == "" {
+		raw, err := clientConfig.RawConfig()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		cluster = raw.CurrentContext
+	}
+	return config, cluster, nil
+}
+
+// loadingRules returns the kubeconfig loading rules for an optional explicit path
+func loadingRules(kubeconfig string) *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	return rules
+}
+
+// Contexts returns the context names defined in the kubeconfig, sorted
+func Contexts(kubeconfig string) ([]string, error) {
+	raw, err := loadingRules(kubeconfig).Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	contexts := make([]string, 0, len(raw.Contexts))
+	for name := range raw.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, nil
+}
+
+// paginate calls page repeatedly, following the continue token returned by
+// each call until the server reports no more results
+func (c *Client) paginate(ctx context.Context, page func(opts metav1.ListOptions) (string, error)) error {
+	opts := metav1.ListOptions{Limit: c.PageSize}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		next, err := page(opts)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		opts.Continue = next
+	}
+}
+
+// ListPods retrieves all pods in a namespace, one page at a time
+func (c *Client) ListPods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	return listAll(ctx, c, c.Clientset.CoreV1().Pods(namespace).List,
+		func(l *corev1.PodList) []corev1.Pod { return l.Items })
+}
+
 // GetPods retrieves pods in a namespace
 func (c *Client) GetPods(ctx context.Context, namespace string) ([]string, error) {
-	pods, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pods, err := c.ListPods(ctx, namespace)
 	if err != nil {
 		return nil, err
 	}
 
 	var podNames []string
-	for _, pod := range pods.Items {
+	for _, pod := range pods {
 		podNames = append(podNames, pod.Name)
 	}
 	return podNames, nil
 }
 
-// CheckConnectivity verifies if the client can connect to the cluster
+// CheckConnectivity verifies if the client can connect to the cluster and
+// records the server version
 func (c *Client) CheckConnectivity(ctx context.Context) error {
-	_, err := c.Clientset.Discovery().ServerVersion()
-	return err
+	info, err := c.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return err
+	}
+	c.ServerVersion = info
+	return nil
 }
@@ -0,0 +1,124 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestListPodsFollowsContinueToken(t *testing.T) {
+	clientset := fake.NewClientset()
+	var limits []int64
+
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).GetListOptions()
+		limits = append(limits, opts.Limit)
+
+		list := &corev1.PodList{}
+		switch opts.Continue {
+		case "":
+			list.Items = []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "a"}}}
+			list.Continue = "page-2"
+		case "page-2":
+			list.Items = []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "b"}}}
+		default:
+			return true, nil, fmt.Errorf("unexpected continue token %q", opts.Continue)
+		}
+		return true, list, nil
+	})
+
+	client := &Client{Clientset: clientset, PageSize: 1}
+	names, err := client.GetPods(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("expected pods from both pages, got %v", names)
+	}
+	if len(limits) != 2 || limits[0] != 1 {
+		t.Errorf("expected two requests with limit 1, got %v", limits)
+	}
+}
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+- name: staging
+  cluster:
+    server: https://staging.example.com
+users:
+- name: auditor
+  user:
+    token: test
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: auditor
+- name: staging
+  context:
+    cluster: staging
+    user: auditor
+current-context: staging
+`
+
+func writeKubeconfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestContextsListsKubeconfigContexts(t *testing.T) {
+	contexts, err := Contexts(writeKubeconfig(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(contexts) != 2 || contexts[0] != "prod" || contexts[1] != "staging" {
+		t.Errorf("expected [prod staging], got %v", contexts)
+	}
+}
+
+func TestNewClientHonorsContextAndImpersonation(t *testing.T) {
+	kubeconfig := writeKubeconfig(t)
+
+	config, cluster, err := loadConfig(Options{Kubeconfig: kubeconfig})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster != "staging" || config.Host != "https://staging.example.com" {
+		t.Errorf("expected the current context to be used, got %s at %s", cluster, config.Host)
+	}
+
+	config, cluster, err = restConfig(Options{
+		Kubeconfig:        kubeconfig,
+		Context:           "prod",
+		Impersonate:       "jane",
+		ImpersonateGroups: []string{"auditors"},
+		RequestTimeout:    5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster != "prod" || config.Host != "https://prod.example.com" {
+		t.Errorf("expected the prod context to be selected, got %s at %s", cluster, config.Host)
+	}
+	if config.Impersonate.UserName != "jane" || len(config.Impersonate.Groups) != 1 || config.Timeout != 5*time.Second {
+		t.Errorf("expected impersonation and timeout to be applied, got %+v timeout=%s", config.Impersonate, config.Timeout)
+	}
+}
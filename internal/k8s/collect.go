@@ -0,0 +1,312 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/logger"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// listAll pages through a typed List call and returns every item
+func listAll[T any, L metav1.ListInterface](ctx context.Context, c *Client, list func(context.Context, metav1.ListOptions) (L, error), items func(L) []T) ([]T, error) {
+	var all []T
+	err := c.paginate(ctx, func(opts metav1.ListOptions) (string, error) {
+		page, err := list(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		all = append(all, items(page)...)
+		return page.GetContinue(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// listDynamic pages through a resource with the dynamic client. APIs served
+// by CRDs are optional: when the CRD is not installed the list is empty
+// instead of failing the collection.
+func listDynamic(ctx context.Context, c *Client, r Resource, namespace string) ([]unstructured.Unstructured, error) {
+	if c.Dynamic == nil {
+		return nil, errors.New("dynamic client is not configured")
+	}
+
+	gvr := schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+	var resource dynamic.ResourceInterface = c.Dynamic.Resource(gvr)
+	if r.Namespaced && namespace != "" {
+		resource = c.Dynamic.Resource(gvr).Namespace(namespace)
+	}
+
+	items, err := listAll(ctx, c, resource.List,
+		func(l *unstructured.UnstructuredList) []unstructured.Unstructured { return l.Items })
+	if apierrors.IsNotFound(err) {
+		logger.Info("API not installed, skipping", "resource", r.String())
+		return nil, nil
+	}
+	return items, err
+}
+
+// collector lists one resource type into the matching Snapshot field
+type collector func(ctx context.Context, c *Client, namespace string, s *Snapshot) error
+
+// collectors maps every supported resource to the function that lists it
+var collectors = map[Resource]collector{
+	ResourcePods: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Pods, err = c.ListPods(ctx, namespace)
+		return err
+	},
+	ResourceNamespaces: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		if namespace != "" {
+			// A namespaced scan only needs its own namespace, which avoids
+			// requiring cluster-wide list permissions
+			ns, err := c.Clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			s.Namespaces = []corev1.Namespace{*ns}
+			return nil
+		}
+		s.Namespaces, err = listAll(ctx, c, c.Clientset.CoreV1().Namespaces().List,
+			func(l *corev1.NamespaceList) []corev1.Namespace { return l.Items })
+		return err
+	},
+	ResourceNodes: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Nodes, err = listAll(ctx, c, c.Clientset.CoreV1().Nodes().List,
+			func(l *corev1.NodeList) []corev1.Node { return l.Items })
+		return err
+	},
+	ResourceKubeletConfigs: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		// Listed separately from ResourceNodes since collectors run concurrently
+		nodes, err := listAll(ctx, c, c.Clientset.CoreV1().Nodes().List,
+			func(l *corev1.NodeList) []corev1.Node { return l.Items })
+		if err != nil {
+			return err
+		}
+		s.KubeletConfigs, err = collectKubeletConfigs(ctx, c, nodes)
+		return err
+	},
+	ResourceServiceAccounts: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ServiceAccounts, err = listAll(ctx, c, c.Clientset.CoreV1().ServiceAccounts(namespace).List,
+			func(l *corev1.ServiceAccountList) []corev1.ServiceAccount { return l.Items })
+		return err
+	},
+	ResourceServices: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Services, err = listAll(ctx, c, c.Clientset.CoreV1().Services(namespace).List,
+			func(l *corev1.ServiceList) []corev1.Service { return l.Items })
+		return err
+	},
+	ResourceNetworkPolicies: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.NetworkPolicies, err = listAll(ctx, c, c.Clientset.NetworkingV1().NetworkPolicies(namespace).List,
+			func(l *networkingv1.NetworkPolicyList) []networkingv1.NetworkPolicy { return l.Items })
+		return err
+	},
+	ResourceIngresses: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Ingresses, err = listAll(ctx, c, c.Clientset.NetworkingV1().Ingresses(namespace).List,
+			func(l *networkingv1.IngressList) []networkingv1.Ingress { return l.Items })
+		return err
+	},
+	ResourceGateways: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Gateways, err = listDynamic(ctx, c, ResourceGateways, namespace)
+		return err
+	},
+	ResourceHTTPRoutes: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.HTTPRoutes, err = listDynamic(ctx, c, ResourceHTTPRoutes, namespace)
+		return err
+	},
+	ResourceReferenceGrants: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ReferenceGrants, err = listDynamic(ctx, c, ResourceReferenceGrants, namespace)
+		return err
+	},
+	ResourceDeployments: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Deployments, err = listAll(ctx, c, c.Clientset.AppsV1().Deployments(namespace).List,
+			func(l *appsv1.DeploymentList) []appsv1.Deployment { return l.Items })
+		return err
+	},
+	ResourceReplicaSets: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ReplicaSets, err = listAll(ctx, c, c.Clientset.AppsV1().ReplicaSets(namespace).List,
+			func(l *appsv1.ReplicaSetList) []appsv1.ReplicaSet { return l.Items })
+		return err
+	},
+	ResourceStatefulSets: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.StatefulSets, err = listAll(ctx, c, c.Clientset.AppsV1().StatefulSets(namespace).List,
+			func(l *appsv1.StatefulSetList) []appsv1.StatefulSet { return l.Items })
+		return err
+	},
+	ResourceDaemonSets: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.DaemonSets, err = listAll(ctx, c, c.Clientset.AppsV1().DaemonSets(namespace).List,
+			func(l *appsv1.DaemonSetList) []appsv1.DaemonSet { return l.Items })
+		return err
+	},
+	ResourceJobs: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Jobs, err = listAll(ctx, c, c.Clientset.BatchV1().Jobs(namespace).List,
+			func(l *batchv1.JobList) []batchv1.Job { return l.Items })
+		return err
+	},
+	ResourceCronJobs: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.CronJobs, err = listAll(ctx, c, c.Clientset.BatchV1().CronJobs(namespace).List,
+			func(l *batchv1.CronJobList) []batchv1.CronJob { return l.Items })
+		return err
+	},
+	ResourceSecrets: func(ctx context.Context, c *Client, namespace string, s *Snapshot) error {
+		secrets, err := listAll(ctx, c, c.Clientset.CoreV1().Secrets(namespace).List,
+			func(l *corev1.SecretList) []corev1.Secret { return l.Items })
+		if err != nil {
+			return err
+		}
+		for i := range secrets {
+			redactSecret(&secrets[i])
+		}
+		s.Secrets = secrets
+		return nil
+	},
+	ResourceConfigMaps: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ConfigMaps, err = listAll(ctx, c, c.Clientset.CoreV1().ConfigMaps(namespace).List,
+			func(l *corev1.ConfigMapList) []corev1.ConfigMap { return l.Items })
+		return err
+	},
+	ResourceRoleBindings: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.RoleBindings, err = listAll(ctx, c, c.Clientset.RbacV1().RoleBindings(namespace).List,
+			func(l *rbacv1.RoleBindingList) []rbacv1.RoleBinding { return l.Items })
+		return err
+	},
+	ResourceClusterRoleBindings: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ClusterRoleBindings, err = listAll(ctx, c, c.Clientset.RbacV1().ClusterRoleBindings().List,
+			func(l *rbacv1.ClusterRoleBindingList) []rbacv1.ClusterRoleBinding { return l.Items })
+		return err
+	},
+	ResourceRoles: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.Roles, err = listAll(ctx, c, c.Clientset.RbacV1().Roles(namespace).List,
+			func(l *rbacv1.RoleList) []rbacv1.Role { return l.Items })
+		return err
+	},
+	ResourceClusterRoles: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ClusterRoles, err = listAll(ctx, c, c.Clientset.RbacV1().ClusterRoles().List,
+			func(l *rbacv1.ClusterRoleList) []rbacv1.ClusterRole { return l.Items })
+		return err
+	},
+	ResourceResourceQuotas: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ResourceQuotas, err = listAll(ctx, c, c.Clientset.CoreV1().ResourceQuotas(namespace).List,
+			func(l *corev1.ResourceQuotaList) []corev1.ResourceQuota { return l.Items })
+		return err
+	},
+	ResourceLimitRanges: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.LimitRanges, err = listAll(ctx, c, c.Clientset.CoreV1().LimitRanges(namespace).List,
+			func(l *corev1.LimitRangeList) []corev1.LimitRange { return l.Items })
+		return err
+	},
+	ResourcePodDisruptionBudgets: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.PodDisruptionBudgets, err = listAll(ctx, c, c.Clientset.PolicyV1().PodDisruptionBudgets(namespace).List,
+			func(l *policyv1.PodDisruptionBudgetList) []policyv1.PodDisruptionBudget { return l.Items })
+		return err
+	},
+	ResourceValidatingWebhooks: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.ValidatingWebhookConfigurations, err = listAll(ctx, c, c.Clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List,
+			func(l *admissionregistrationv1.ValidatingWebhookConfigurationList) []admissionregistrationv1.ValidatingWebhookConfiguration {
+				return l.Items
+			})
+		return err
+	},
+	ResourceMutatingWebhooks: func(ctx context.Context, c *Client, namespace string, s *Snapshot) (err error) {
+		s.MutatingWebhookConfigurations, err = listAll(ctx, c, c.Clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List,
+			func(l *admissionregistrationv1.MutatingWebhookConfigurationList) []admissionregistrationv1.MutatingWebhookConfiguration {
+				return l.Items
+			})
+		return err
+	},
+}
+
+// LastAppliedAnnotation holds the full manifest applied by kubectl, values included
+const LastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// redactSecret drops every secret value while keeping the keys, so scanners
+// and exported archives can reason about a Secret without ever holding its data
+func redactSecret(secret *corev1.Secret) {
+	for key := range secret.Data {
+		secret.Data[key] = []byte{}
+	}
+	for key := range secret.StringData {
+		secret.StringData[key] = ""
+	}
+	delete(secret.Annotations, LastAppliedAnnotation)
+	secret.ManagedFields = nil
+}
+
+// Collect lists each requested resource exactly once, running up to workers
+// List calls concurrently. Resources that fail to list are left out of
+// Snapshot.Collected, recorded in Snapshot.Errors and reported in the returned
+// error; the snapshot is still usable for everything that succeeded.
+func Collect(ctx context.Context, c *Client, namespace string, resources []Resource, workers int) (*Snapshot, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	snap := &Snapshot{Cluster: c.Cluster, Namespace: namespace, CapturedAt: time.Now().UTC()}
+	if c.ServerVersion != nil {
+		snap.ServerVersion = c.ServerVersion.GitVersion
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+
+	seen := map[Resource]bool{}
+	for _, r := range resources {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+
+		collect, ok := collectors[r]
+		if !ok {
+			err := fmt.Errorf("%s: unsupported resource", r)
+			mu.Lock()
+			errs = append(errs, err)
+			snap.Errors = append(snap.Errors, CollectError{Resource: r.String(), Namespace: namespace, Reason: ReasonUnsupported, Message: err.Error()})
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(r Resource, collect collector) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Each collector writes a distinct Snapshot field, so only the
+			// shared bookkeeping needs the lock
+			err := collect(ctx, c, namespace, snap)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", r, err))
+				snap.Errors = append(snap.Errors, CollectError{Resource: r.String(), Namespace: namespace, Reason: ErrorReason(err), Message: err.Error()})
+				return
+			}
+			snap.markCollected(r)
+		}(r, collect)
+	}
+	wg.Wait()
+
+	// Goroutines finish in any order; keep the error list stable
+	sort.Slice(snap.Errors, func(i, j int) bool { return snap.Errors[i].Resource < snap.Errors[j].Resource })
+
+	return snap, errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// Reasons used to classify why a resource could not be read
+const (
+	ReasonForbidden    = "Forbidden"
+	ReasonUnauthorized = "Unauthorized"
+	ReasonTimeout      = "Timeout"
+	ReasonUnsupported  = "Unsupported"
+	ReasonError        = "Error"
+	// ReasonExcluded marks a resource the user chose not to read
+	ReasonExcluded = "Excluded"
+)
+
+// CollectError records a resource that could not be listed. A resource that
+// was collected may also carry one when part of it could not be read, such
+// as kubelet flags that override a configuration file.
+type CollectError struct {
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+// ErrorReason classifies an API error into one of the Reason constants
+func ErrorReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case apierrors.IsForbidden(err):
+		return ReasonForbidden
+	case apierrors.IsUnauthorized(err):
+		return ReasonUnauthorized
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return ReasonTimeout
+	case apierrors.IsNotFound(err), meta.IsNoMatchError(err), apierrors.IsMethodNotSupported(err):
+		// A List returning NotFound means the server does not serve the resource
+		return ReasonUnsupported
+	default:
+		return ReasonError
+	}
+}
@@ -0,0 +1,62 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymlinks bounds how many links HostPath follows before giving up,
+// which also ends symlink loops
+const maxSymlinks = 40
+
+// HostPath returns where an absolute path on a node lives below root, the
+// mount point of the node's filesystem. Symlinks are resolved as the node
+// would resolve them: absolute targets start again at root and ".." never
+// climbs above it, so a link on the node cannot point outside the mount.
+// Missing components are kept as they are.
+func HostPath(root, nodePath string) (string, error) {
+	current := "/"
+	pending := strings.Split(nodePath, "/")
+	links := 0
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			current = path.Dir(current)
+			continue
+		}
+
+		next := path.Join(current, part)
+		info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(next)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", fmt.Errorf("too many symlinks resolving %s", nodePath)
+		}
+		target, err := os.Readlink(filepath.Join(root, filepath.FromSlash(next)))
+		if err != nil {
+			return "", err
+		}
+		target = filepath.ToSlash(target)
+		if path.IsAbs(target) {
+			current = "/"
+		}
+		pending = append(strings.Split(target, "/"), pending...)
+	}
+	return filepath.Join(root, filepath.FromSlash(current)), nil
+}
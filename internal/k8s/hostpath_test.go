@@ -0,0 +1,45 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHostPath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "etc/kubernetes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"etc/kubernetes/absolute": "/var/lib/kubelet",
+		"etc/kubernetes/escape":   "../../../../secret",
+		"etc/kubernetes/loop":     "loop",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]string{
+		"/etc/kubernetes/admin.conf":      "etc/kubernetes/admin.conf",
+		"/etc/kubernetes/absolute/config": "var/lib/kubelet/config",
+		"/etc/kubernetes/escape":          "secret",
+		"/../../etc/kubernetes":           "etc/kubernetes",
+	}
+	for nodePath, want := range tests {
+		got, err := HostPath(root, nodePath)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", nodePath, err)
+			continue
+		}
+		if want := filepath.Join(root, want); got != want {
+			t.Errorf("%s: expected %s, got %s", nodePath, want, got)
+		}
+	}
+
+	if _, err := HostPath(root, "/etc/kubernetes/loop"); err == nil {
+		t.Error("expected an error for a symlink loop")
+	}
+}
@@ -0,0 +1,278 @@
+package k8s
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kubeletUnitDirs are where systemd looks for the kubelet service, lowest
+// precedence first
+var kubeletUnitDirs = []string{
+	"/lib/systemd/system",
+	"/usr/lib/systemd/system",
+	"/etc/systemd/system",
+}
+
+// kubeletUnit is the parts of the kubelet's systemd unit that build its
+// command line
+type kubeletUnit struct {
+	execStart string
+	env       map[string]string
+}
+
+// ReadKubeletFlags returns the command line flags the kubelet is started
+// with on a node mounted at root, read from its systemd unit, drop-ins such
+// as kubeadm's 10-kubeadm.conf, and the environment files they reference.
+// Flags are keyed without dashes; a flag without a value maps to "true".
+// It returns nil when the node has no kubelet systemd unit.
+func ReadKubeletFlags(root string) (map[string]string, error) {
+	// The last directory holding the unit wins; drop-ins from every
+	// directory apply in file name order, later directories replacing
+	// files of the same name
+	var unitFile string
+	dropIns := map[string]string{}
+	for _, dir := range kubeletUnitDirs {
+		file := path.Join(dir, "kubelet.service")
+		hostPath, err := HostPath(root, file)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(hostPath); err == nil {
+			unitFile = file
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		hostDir, err := HostPath(root, file+".d")
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(hostDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".conf") {
+				dropIns[entry.Name()] = path.Join(file+".d", entry.Name())
+			}
+		}
+	}
+	if unitFile == "" && len(dropIns) == 0 {
+		return nil, nil
+	}
+
+	files := []string{}
+	if unitFile != "" {
+		files = append(files, unitFile)
+	}
+	names := make([]string, 0, len(dropIns))
+	for name := range dropIns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		files = append(files, dropIns[name])
+	}
+
+	unit := &kubeletUnit{env: map[string]string{}}
+	for _, file := range files {
+		if err := unit.read(root, file); err != nil {
+			return nil, err
+		}
+	}
+	return unit.flags(), nil
+}
+
+// read applies the [Service] settings of one unit file
+func (u *kubeletUnit) read(root, file string) error {
+	lines, err := readUnitLines(root, file)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "ExecStart":
+			// An empty ExecStart clears the command set by earlier files
+			u.execStart = strings.TrimLeft(value, "@-:+!")
+		case "Environment":
+			for _, assignment := range splitWords(value) {
+				if name, val, ok := strings.Cut(assignment, "="); ok {
+					u.env[name] = val
+				}
+			}
+		case "EnvironmentFile":
+			optional := strings.HasPrefix(value, "-")
+			if err := u.readEnvironmentFile(root, strings.TrimPrefix(value, "-")); err != nil {
+				if optional && errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return fmt.Errorf("EnvironmentFile of %s: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readEnvironmentFile adds the KEY=value lines of an EnvironmentFile
+func (u *kubeletUnit) readEnvironmentFile(root, file string) error {
+	lines, err := readUnitLines(root, file)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if name, val, ok := strings.Cut(line, "="); ok {
+			words := splitWords(val)
+			u.env[strings.TrimSpace(name)] = strings.Join(words, " ")
+		}
+	}
+	return nil
+}
+
+// flags expands the environment in ExecStart and parses its flags
+func (u *kubeletUnit) flags() map[string]string {
+	command := os.Expand(u.execStart, func(name string) string {
+		return u.env[name]
+	})
+	flags := map[string]string{}
+	words := splitWords(command)
+	for i := 0; i < len(words); i++ {
+		name, ok := strings.CutPrefix(words[i], "--")
+		if !ok {
+			continue
+		}
+		if name, value, ok := strings.Cut(name, "="); ok {
+			flags[name] = value
+			continue
+		}
+		if i+1 < len(words) && !strings.HasPrefix(words[i+1], "-") {
+			flags[name] = words[i+1]
+			i++
+			continue
+		}
+		flags[name] = "true"
+	}
+	return flags
+}
+
+// readUnitLines returns the non-comment lines of a unit or environment file
+// on the node, joining lines continued with a backslash
+func readUnitLines(root, file string) ([]string, error) {
+	hostPath, err := HostPath(root, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	f, err := os.Open(hostPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	var current strings.Builder
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if current.Len() == 0 && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";")) {
+			continue
+		}
+		if continued, ok := strings.CutSuffix(line, "\\"); ok {
+			current.WriteString(continued + " ")
+			continue
+		}
+		current.WriteString(line)
+		lines = append(lines, current.String())
+		current.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if current.Len() > 0 {
+		lines = append(lines, current.String())
+	}
+	return lines, nil
+}
+
+// splitWords splits a command line on whitespace, keeping quoted words together
+func splitWords(s string) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// ApplyFlags overrides the configuration with the kubelet command line flags
+// that take precedence over the configuration file
+func (c *KubeletConfig) ApplyFlags(flags map[string]string) error {
+	parseBool := func(name string) (*bool, error) {
+		value, err := strconv.ParseBool(flags[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubelet flag --%s=%s", name, flags[name])
+		}
+		return &value, nil
+	}
+
+	var err error
+	if _, ok := flags["anonymous-auth"]; ok {
+		if c.Authentication.Anonymous.Enabled, err = parseBool("anonymous-auth"); err != nil {
+			return err
+		}
+	}
+	if _, ok := flags["authentication-token-webhook"]; ok {
+		if c.Authentication.Webhook.Enabled, err = parseBool("authentication-token-webhook"); err != nil {
+			return err
+		}
+	}
+	if _, ok := flags["protect-kernel-defaults"]; ok {
+		if c.ProtectKernelDefaults, err = parseBool("protect-kernel-defaults"); err != nil {
+			return err
+		}
+	}
+	if mode, ok := flags["authorization-mode"]; ok {
+		c.Authorization.Mode = mode
+	}
+	if value, ok := flags["read-only-port"]; ok {
+		port, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid kubelet flag --read-only-port=%s", value)
+		}
+		readOnlyPort := int32(port)
+		c.ReadOnlyPort = &readOnlyPort
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadKubeletFlags(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if flags, err := ReadKubeletFlags(root); err != nil || flags != nil {
+		t.Fatalf("expected no flags without a kubelet unit, got %v, %v", flags, err)
+	}
+
+	// The layout kubeadm installs, plus an operator drop-in
+	write("usr/lib/systemd/system/kubelet.service", "[Service]\nExecStart=/usr/bin/kubelet\n")
+	write("usr/lib/systemd/system/kubelet.service.d/10-kubeadm.conf", `[Service]
+Environment="KUBELET_KUBECONFIG_ARGS=--bootstrap-kubeconfig=/etc/kubernetes/bootstrap-kubelet.conf --kubeconfig=/etc/kubernetes/kubelet.conf"
+Environment="KUBELET_CONFIG_ARGS=--config=/var/lib/kubelet/config.yaml"
+EnvironmentFile=-/var/lib/kubelet/kubeadm-flags.env
+EnvironmentFile=-/etc/default/kubelet
+ExecStart=
+ExecStart=/usr/bin/kubelet $KUBELET_KUBECONFIG_ARGS $KUBELET_CONFIG_ARGS \
+  $KUBELET_KUBEADM_ARGS $KUBELET_EXTRA_ARGS
+`)
+	write("var/lib/kubelet/kubeadm-flags.env", `KUBELET_KUBEADM_ARGS="--container-runtime-endpoint=unix:///run/containerd/containerd.sock"`+"\n")
+	write("etc/default/kubelet", "KUBELET_EXTRA_ARGS=--anonymous-auth=true --read-only-port 10255 --protect-kernel-defaults\n")
+
+	flags, err := ReadKubeletFlags(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"bootstrap-kubeconfig":       "/etc/kubernetes/bootstrap-kubelet.conf",
+		"kubeconfig":                 "/etc/kubernetes/kubelet.conf",
+		"config":                     "/var/lib/kubelet/config.yaml",
+		"container-runtime-endpoint": "unix:///run/containerd/containerd.sock",
+		"anonymous-auth":             "true",
+		"read-only-port":             "10255",
+		"protect-kernel-defaults":    "true",
+	}
+	if len(flags) != len(want) {
+		t.Errorf("expected %d flags, got %v", len(want), flags)
+	}
+	for name, value := range want {
+		if flags[name] != value {
+			t.Errorf("--%s: expected %q, got %q", name, value, flags[name])
+		}
+	}
+
+	// Flags win over the configuration file
+	config := &KubeletConfig{}
+	disabled := false
+	config.Authentication.Anonymous.Enabled = &disabled
+	if err := config.ApplyFlags(flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if anonymous := config.Authentication.Anonymous.Enabled; anonymous == nil || !*anonymous {
+		t.Error("expected --anonymous-auth to override the configuration file")
+	}
+	if port := config.ReadOnlyPort; port == nil || *port != 10255 {
+		t.Errorf("expected the read-only port from the flags, got %v", port)
+	}
+	if err := config.ApplyFlags(map[string]string{"read-only-port": "none"}); err == nil {
+		t.Error("expected an error for an invalid flag value")
+	}
+
+	// A required EnvironmentFile that is missing is named in the error
+	write("etc/systemd/system/kubelet.service.d/20-extra.conf", "[Service]\nEnvironmentFile=/etc/kubelet/extra.env\n")
+	if _, err := ReadKubeletFlags(root); err == nil || !strings.Contains(err.Error(), "/etc/kubelet/extra.env") {
+		t.Errorf("expected the missing environment file to be named, got %v", err)
+	}
+}
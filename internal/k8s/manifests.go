@@ -0,0 +1,97 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Manifest is an object read from a file rather than from the cluster
+type Manifest struct {
+	Path       string `json:"path"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// manifestExtensions are the file types LoadManifests reads
+var manifestExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// LoadManifests reads every object from a YAML or JSON file, or from all
+// such files below a directory. Multi-document files and List objects are
+// expanded; documents without apiVersion and kind are skipped.
+func LoadManifests(path string) ([]Manifest, error) {
+	var manifests []Manifest
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (file != path && !manifestExtensions[strings.ToLower(filepath.Ext(file))]) {
+			return nil
+		}
+		found, err := readManifestFile(file)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+func readManifestFile(path string) ([]Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var manifests []Manifest
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return manifests, nil
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			continue
+		}
+
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				manifests = append(manifests, manifestFor(path, item.(*unstructured.Unstructured)))
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read list in %s: %w", path, err)
+			}
+			continue
+		}
+		manifests = append(manifests, manifestFor(path, obj))
+	}
+}
+
+func manifestFor(path string, obj *unstructured.Unstructured) Manifest {
+	return Manifest{
+		Path:       path,
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+	}
+}
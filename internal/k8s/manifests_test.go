@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app.yaml": `apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+  namespace: shop
+---
+# values for a chart, not an object
+replicas: 3
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: batch/v1beta1
+  kind: CronJob
+  metadata:
+    name: cleanup
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+`,
+		"nested/rbac.json": `{"apiVersion":"rbac.authorization.k8s.io/v1beta1","kind":"ClusterRole","metadata":{"name":"reader"}}`,
+		"README.md":        "apiVersion: apps/v1beta1\nkind: Deployment\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifests, err := LoadManifests(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"Ingress/web":        "extensions/v1beta1",
+		"CronJob/cleanup":    "batch/v1beta1",
+		"Service/web":        "v1",
+		"ClusterRole/reader": "rbac.authorization.k8s.io/v1beta1",
+	}
+	if len(manifests) != len(want) {
+		t.Fatalf("expected %d manifests, got %d: %+v", len(want), len(manifests), manifests)
+	}
+	for _, m := range manifests {
+		if got := want[m.Kind+"/"+m.Name]; got != m.APIVersion {
+			t.Errorf("%s/%s: expected apiVersion %q, got %q", m.Kind, m.Name, got, m.APIVersion)
+		}
+	}
+
+	single, err := LoadManifests(filepath.Join(dir, "nested", "rbac.json"))
+	if err != nil || len(single) != 1 {
+		t.Fatalf("expected one manifest from a single file, got %d (%v)", len(single), err)
+	}
+}
@@ -0,0 +1,116 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/logger"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// KubeletConfigPath is where kubeadm and most distributions keep the
+// kubelet's KubeletConfiguration file
+const KubeletConfigPath = "/var/lib/kubelet/config.yaml"
+
+// kubeletConfigWorkers bounds how many nodes are asked for their kubelet
+// configuration at the same time
+const kubeletConfigWorkers = 8
+
+// KubeletConfig holds the security-relevant fields of a kubelet's
+// configuration, as served by the kubelet's /configz endpoint or read from
+// its configuration file
+type KubeletConfig struct {
+	Authentication struct {
+		Anonymous struct {
+			Enabled *bool `json:"enabled,omitempty"`
+		} `json:"anonymous"`
+		Webhook struct {
+			Enabled *bool `json:"enabled,omitempty"`
+		} `json:"webhook"`
+	} `json:"authentication"`
+	Authorization struct {
+		Mode string `json:"mode,omitempty"`
+	} `json:"authorization"`
+	ReadOnlyPort          *int32 `json:"readOnlyPort,omitempty"`
+	ProtectKernelDefaults *bool  `json:"protectKernelDefaults,omitempty"`
+}
+
+// GetKubeletConfig reads a node's kubelet configuration through the API
+// server's nodes/proxy subresource, which requires 'get' on nodes/proxy
+func (c *Client) GetKubeletConfig(ctx context.Context, node string) (*KubeletConfig, error) {
+	data, err := c.Clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").Name(node).SubResource("proxy").Suffix("configz").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var configz struct {
+		KubeletConfig *KubeletConfig `json:"kubeletconfig"`
+	}
+	if err := json.Unmarshal(data, &configz); err != nil {
+		return nil, fmt.Errorf("failed to decode kubelet configuration of node %s: %w", node, err)
+	}
+	if configz.KubeletConfig == nil {
+		return nil, fmt.Errorf("node %s returned no kubelet configuration", node)
+	}
+	return configz.KubeletConfig, nil
+}
+
+// ReadKubeletConfigFile parses a KubeletConfiguration file. Fields left out
+// of the file keep the secure defaults of the kubelet.config.k8s.io/v1beta1
+// API, which is also how the scanners treat them.
+func ReadKubeletConfigFile(path string) (*KubeletConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &KubeletConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse kubelet configuration %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// collectKubeletConfigs fetches the kubelet configuration of every node.
+// Nodes that cannot be reached are skipped; a permission error fails the
+// whole resource so scanners depending on it are reported as incomplete.
+func collectKubeletConfigs(ctx context.Context, c *Client, nodes []corev1.Node) (map[string]KubeletConfig, error) {
+	configs := map[string]KubeletConfig{}
+	sem := make(chan struct{}, kubeletConfigWorkers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var denied error
+
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			config, err := c.GetKubeletConfig(ctx, name)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch reason := ErrorReason(err); {
+			case err == nil:
+				configs[name] = *config
+			case reason == ReasonForbidden || reason == ReasonUnauthorized:
+				denied = err
+			default:
+				logger.Warn("Could not read kubelet configuration, skipping node", "node", name, "error", err)
+			}
+		}(node.Name)
+	}
+	wg.Wait()
+
+	if denied != nil {
+		return nil, denied
+	}
+	return configs, nil
+}
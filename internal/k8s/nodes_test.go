@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestCollectKubeletConfigsSkipsUnreachableNodes(t *testing.T) {
+	forbidden := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case forbidden:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+		case r.URL.Path == "/api/v1/nodes/worker-1/proxy/configz":
+			w.Write([]byte(`{"kubeletconfig":{"authentication":{"anonymous":{"enabled":true}},"authorization":{"mode":"AlwaysAllow"},"readOnlyPort":10255}}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &Client{Clientset: clientset}
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "worker-2"}},
+	}
+
+	configs, err := collectKubeletConfigs(context.Background(), client, nodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(configs) != 1 {
+		t.Fatalf("expected only the reachable node, got %v", configs)
+	}
+	config := configs["worker-1"]
+	if config.Authentication.Anonymous.Enabled == nil || !*config.Authentication.Anonymous.Enabled ||
+		config.Authorization.Mode != "AlwaysAllow" || config.ReadOnlyPort == nil || *config.ReadOnlyPort != 10255 {
+		t.Errorf("unexpected kubelet configuration: %+v", config)
+	}
+
+	// Missing nodes/proxy permission fails the whole resource
+	forbidden = true
+	if _, err := collectKubeletConfigs(context.Background(), client, nodes); ErrorReason(err) != ReasonForbidden {
+		t.Errorf("expected a Forbidden error, got %v", err)
+	}
+}
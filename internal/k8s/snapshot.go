@@ -0,0 +1,593 @@
+package k8s
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Resource identifies an API resource the snapshot layer knows how to collect
+type Resource struct {
+	Group      string
+	Version    string
+	Resource   string
+	Kind       string
+	Namespaced bool
+}
+
+// String returns the resource in kubectl notation, e.g. "deployments.apps"
+func (r Resource) String() string {
+	if r.Group == "" {
+		return r.Resource
+	}
+	return r.Resource + "." + r.Group
+}
+
+var (
+	ResourcePods                 = Resource{Version: "v1", Resource: "pods", Kind: "Pod", Namespaced: true}
+	ResourceNamespaces           = Resource{Version: "v1", Resource: "namespaces", Kind: "Namespace"}
+	ResourceNodes                = Resource{Version: "v1", Resource: "nodes", Kind: "Node"}
+	ResourceServiceAccounts      = Resource{Version: "v1", Resource: "serviceaccounts", Kind: "ServiceAccount", Namespaced: true}
+	ResourceServices             = Resource{Version: "v1", Resource: "services", Kind: "Service", Namespaced: true}
+	ResourceNetworkPolicies      = Resource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies", Kind: "NetworkPolicy", Namespaced: true}
+	ResourceIngresses            = Resource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses", Kind: "Ingress", Namespaced: true}
+	ResourceGateways             = Resource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways", Kind: "Gateway", Namespaced: true}
+	ResourceHTTPRoutes           = Resource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes", Kind: "HTTPRoute", Namespaced: true}
+	ResourceReferenceGrants      = Resource{Group: "gateway.networking.k8s.io", Version: "v1beta1", Resource: "referencegrants", Kind: "ReferenceGrant", Namespaced: true}
+	ResourceDeployments          = Resource{Group: "apps", Version: "v1", Resource: "deployments", Kind: "Deployment", Namespaced: true}
+	ResourceReplicaSets          = Resource{Group: "apps", Version: "v1", Resource: "replicasets", Kind: "ReplicaSet", Namespaced: true}
+	ResourceStatefulSets         = Resource{Group: "apps", Version: "v1", Resource: "statefulsets", Kind: "StatefulSet", Namespaced: true}
+	ResourceDaemonSets           = Resource{Group: "apps", Version: "v1", Resource: "daemonsets", Kind: "DaemonSet", Namespaced: true}
+	ResourceJobs                 = Resource{Group: "batch", Version: "v1", Resource: "jobs", Kind: "Job", Namespaced: true}
+	ResourceCronJobs             = Resource{Group: "batch", Version: "v1", Resource: "cronjobs", Kind: "CronJob", Namespaced: true}
+	ResourceSecrets              = Resource{Version: "v1", Resource: "secrets", Kind: "Secret", Namespaced: true}
+	ResourceConfigMaps           = Resource{Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespaced: true}
+	ResourceRoleBindings         = Resource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings", Kind: "RoleBinding", Namespaced: true}
+	ResourceClusterRoleBindings  = Resource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings", Kind: "ClusterRoleBinding"}
+	ResourceRoles                = Resource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles", Kind: "Role", Namespaced: true}
+	ResourceClusterRoles         = Resource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles", Kind: "ClusterRole"}
+	ResourceResourceQuotas       = Resource{Version: "v1", Resource: "resourcequotas", Kind: "ResourceQuota", Namespaced: true}
+	ResourceLimitRanges          = Resource{Version: "v1", Resource: "limitranges", Kind: "LimitRange", Namespaced: true}
+	ResourcePodDisruptionBudgets = Resource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets", Kind: "PodDisruptionBudget", Namespaced: true}
+	ResourceValidatingWebhooks   = Resource{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "validatingwebhookconfigurations", Kind: "ValidatingWebhookConfiguration"}
+	ResourceMutatingWebhooks     = Resource{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "mutatingwebhookconfigurations", Kind: "MutatingWebhookConfiguration"}
+)
+
+// ResourceKubeletConfigs is read node by node through the nodes/proxy
+// subresource. That permission also reaches the kubelet's exec and log
+// endpoints, so it is left out of AllResources and only collected on request.
+var ResourceKubeletConfigs = Resource{Version: "v1", Resource: "nodes/proxy", Kind: "Node"}
+
+// WorkloadResources are the controllers needed to resolve a pod to its owning workload
+var WorkloadResources = []Resource{
+	ResourceDeployments,
+	ResourceReplicaSets,
+	ResourceStatefulSets,
+	ResourceDaemonSets,
+	ResourceJobs,
+	ResourceCronJobs,
+}
+
+// AllResources lists every resource the snapshot layer can collect
+var AllResources = append([]Resource{
+	ResourcePods,
+	ResourceNamespaces,
+	ResourceNodes,
+	ResourceServiceAccounts,
+	ResourceServices,
+	ResourceNetworkPolicies,
+	ResourceIngresses,
+	ResourceGateways,
+	ResourceHTTPRoutes,
+	ResourceReferenceGrants,
+	ResourceSecrets,
+	ResourceConfigMaps,
+	ResourceRoleBindings,
+	ResourceClusterRoleBindings,
+	ResourceRoles,
+	ResourceClusterRoles,
+	ResourceResourceQuotas,
+	ResourceLimitRanges,
+	ResourcePodDisruptionBudgets,
+	ResourceValidatingWebhooks,
+	ResourceMutatingWebhooks,
+}, WorkloadResources...)
+
+// Snapshot is a point-in-time copy of the cluster objects scanners read.
+// Each resource type is listed once and shared by every scanner.
+type Snapshot struct {
+	// Cluster names the cluster the objects were captured from
+	Cluster string `json:"cluster"`
+	// Namespace is the namespace the snapshot was limited to, empty for all namespaces
+	Namespace string `json:"namespace"`
+	// CapturedAt records when the objects were listed
+	CapturedAt time.Time `json:"capturedAt"`
+	// ServerVersion is the API server's git version, e.g. "v1.30.4", when known
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// Collected lists the resources that were successfully captured
+	Collected []string `json:"collected"`
+	// Errors lists the resources that could not be captured and why
+	Errors []CollectError `json:"errors,omitempty"`
+
+	Pods       []corev1.Pod       `json:"pods,omitempty"`
+	Namespaces []corev1.Namespace `json:"namespaces,omitempty"`
+	Nodes      []corev1.Node      `json:"nodes,omitempty"`
+	// KubeletConfigs is keyed by node name
+	KubeletConfigs  map[string]KubeletConfig     `json:"kubeletConfigs,omitempty"`
+	ServiceAccounts []corev1.ServiceAccount      `json:"serviceAccounts,omitempty"`
+	Services        []corev1.Service             `json:"services,omitempty"`
+	NetworkPolicies []networkingv1.NetworkPolicy `json:"networkPolicies,omitempty"`
+	Ingresses       []networkingv1.Ingress       `json:"ingresses,omitempty"`
+	// Gateway API objects are read through the dynamic client and stay
+	// unstructured, so no Gateway API client library is needed
+	Gateways        []unstructured.Unstructured `json:"gateways,omitempty"`
+	HTTPRoutes      []unstructured.Unstructured `json:"httpRoutes,omitempty"`
+	ReferenceGrants []unstructured.Unstructured `json:"referenceGrants,omitempty"`
+	Deployments     []appsv1.Deployment         `json:"deployments,omitempty"`
+	ReplicaSets     []appsv1.ReplicaSet         `json:"replicaSets,omitempty"`
+	StatefulSets    []appsv1.StatefulSet        `json:"statefulSets,omitempty"`
+	DaemonSets      []appsv1.DaemonSet          `json:"daemonSets,omitempty"`
+	Jobs            []batchv1.Job               `json:"jobs,omitempty"`
+	CronJobs        []batchv1.CronJob           `json:"cronJobs,omitempty"`
+	// Secrets never carry their values; see redactSecret
+	Secrets             []corev1.Secret             `json:"secrets,omitempty"`
+	ConfigMaps          []corev1.ConfigMap          `json:"configMaps,omitempty"`
+	RoleBindings        []rbacv1.RoleBinding        `json:"roleBindings,omitempty"`
+	ClusterRoleBindings []rbacv1.ClusterRoleBinding `json:"clusterRoleBindings,omitempty"`
+	Roles               []rbacv1.Role               `json:"roles,omitempty"`
+	ClusterRoles        []rbacv1.ClusterRole        `json:"clusterRoles,omitempty"`
+	ResourceQuotas      []corev1.ResourceQuota      `json:"resourceQuotas,omitempty"`
+	LimitRanges         []corev1.LimitRange         `json:"limitRanges,omitempty"`
+
+	PodDisruptionBudgets            []policyv1.PodDisruptionBudget                           `json:"podDisruptionBudgets,omitempty"`
+	ValidatingWebhookConfigurations []admissionregistrationv1.ValidatingWebhookConfiguration `json:"validatingWebhookConfigurations,omitempty"`
+	MutatingWebhookConfigurations   []admissionregistrationv1.MutatingWebhookConfiguration   `json:"mutatingWebhookConfigurations,omitempty"`
+
+	indexOnce sync.Once
+	idx       *snapshotIndex
+}
+
+// Has reports whether the given resource was captured in the snapshot
+func (s *Snapshot) Has(r Resource) bool {
+	for _, name := range s.Collected {
+		if name == r.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorFor returns the collection error for a resource, if listing it failed
+func (s *Snapshot) ErrorFor(r Resource) *CollectError {
+	for i := range s.Errors {
+		if s.Errors[i].Resource == r.String() {
+			return &s.Errors[i]
+		}
+	}
+	return nil
+}
+
+// markCollected records a resource as captured, keeping the list sorted
+func (s *Snapshot) markCollected(r Resource) {
+	if s.Has(r) {
+		return
+	}
+	s.Collected = append(s.Collected, r.String())
+	sort.Strings(s.Collected)
+}
+
+// WorkloadRef identifies the top-level controller that owns a pod
+type WorkloadRef struct {
+	Kind      string `json:"kind" yaml:"kind"`
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+}
+
+// snapshotIndex holds lookup tables built on first use
+type snapshotIndex struct {
+	podsByNamespace map[string][]*corev1.Pod
+	podsByLabel     map[string][]*corev1.Pod
+	podsByOwner     map[types.UID][]*corev1.Pod
+	owners          map[types.UID]metav1.ObjectMeta
+	namespaces      map[string]*corev1.Namespace
+	serviceAccounts map[string]*corev1.ServiceAccount
+	servicesByNS    map[string][]*corev1.Service
+	netpolsByNS     map[string][]*networkingv1.NetworkPolicy
+	pdbsByNS        map[string][]*policyv1.PodDisruptionBudget
+	// ingressesByService is keyed by "<namespace>/<service>"
+	ingressesByService map[string][]*networkingv1.Ingress
+	// grantsBySubject is keyed by "<namespace>/<name>" for service accounts,
+	// plus "<namespace>/*" and "*" for the system:serviceaccounts groups
+	grantsBySubject map[string][]RoleGrant
+	roles           map[string]*rbacv1.Role
+	quotasByNS      map[string][]*corev1.ResourceQuota
+	limitRangesByNS map[string][]*corev1.LimitRange
+	clusterRoles    map[string]*rbacv1.ClusterRole
+}
+
+func namespacedKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func labelKey(key, value string) string {
+	return key + "=" + value
+}
+
+func (s *Snapshot) index() *snapshotIndex {
+	s.indexOnce.Do(func() {
+		idx := &snapshotIndex{
+			podsByNamespace:    map[string][]*corev1.Pod{},
+			podsByLabel:        map[string][]*corev1.Pod{},
+			podsByOwner:        map[types.UID][]*corev1.Pod{},
+			owners:             map[types.UID]metav1.ObjectMeta{},
+			namespaces:         map[string]*corev1.Namespace{},
+			serviceAccounts:    map[string]*corev1.ServiceAccount{},
+			servicesByNS:       map[string][]*corev1.Service{},
+			netpolsByNS:        map[string][]*networkingv1.NetworkPolicy{},
+			pdbsByNS:           map[string][]*policyv1.PodDisruptionBudget{},
+			ingressesByService: map[string][]*networkingv1.Ingress{},
+			grantsBySubject:    map[string][]RoleGrant{},
+			roles:              map[string]*rbacv1.Role{},
+			quotasByNS:         map[string][]*corev1.ResourceQuota{},
+			limitRangesByNS:    map[string][]*corev1.LimitRange{},
+			clusterRoles:       map[string]*rbacv1.ClusterRole{},
+		}
+
+		for i := range s.Pods {
+			pod := &s.Pods[i]
+			idx.podsByNamespace[pod.Namespace] = append(idx.podsByNamespace[pod.Namespace], pod)
+			for k, v := range pod.Labels {
+				key := namespacedKey(pod.Namespace, labelKey(k, v))
+				idx.podsByLabel[key] = append(idx.podsByLabel[key], pod)
+			}
+			for _, ref := range pod.OwnerReferences {
+				idx.podsByOwner[ref.UID] = append(idx.podsByOwner[ref.UID], pod)
+			}
+		}
+
+		for i := range s.Namespaces {
+			idx.namespaces[s.Namespaces[i].Name] = &s.Namespaces[i]
+		}
+		for i := range s.ServiceAccounts {
+			sa := &s.ServiceAccounts[i]
+			idx.serviceAccounts[namespacedKey(sa.Namespace, sa.Name)] = sa
+		}
+		for i := range s.Services {
+			svc := &s.Services[i]
+			idx.servicesByNS[svc.Namespace] = append(idx.servicesByNS[svc.Namespace], svc)
+		}
+		for i := range s.NetworkPolicies {
+			np := &s.NetworkPolicies[i]
+			idx.netpolsByNS[np.Namespace] = append(idx.netpolsByNS[np.Namespace], np)
+		}
+		for i := range s.PodDisruptionBudgets {
+			pdb := &s.PodDisruptionBudgets[i]
+			idx.pdbsByNS[pdb.Namespace] = append(idx.pdbsByNS[pdb.Namespace], pdb)
+		}
+
+		for i := range s.Ingresses {
+			ing := &s.Ingresses[i]
+			for _, name := range IngressBackendServices(ing) {
+				key := namespacedKey(ing.Namespace, name)
+				idx.ingressesByService[key] = append(idx.ingressesByService[key], ing)
+			}
+		}
+
+		for i := range s.ResourceQuotas {
+			quota := &s.ResourceQuotas[i]
+			idx.quotasByNS[quota.Namespace] = append(idx.quotasByNS[quota.Namespace], quota)
+		}
+		for i := range s.LimitRanges {
+			lr := &s.LimitRanges[i]
+			idx.limitRangesByNS[lr.Namespace] = append(idx.limitRangesByNS[lr.Namespace], lr)
+		}
+
+		for _, rb := range s.RoleBindings {
+			idx.addGrants(RoleGrant{Binding: "RoleBinding/" + rb.Name, Namespace: rb.Namespace, RoleRef: rb.RoleRef}, rb.Subjects)
+		}
+		for _, crb := range s.ClusterRoleBindings {
+			idx.addGrants(RoleGrant{Binding: "ClusterRoleBinding/" + crb.Name, RoleRef: crb.RoleRef}, crb.Subjects)
+		}
+		for i := range s.Roles {
+			role := &s.Roles[i]
+			idx.roles[namespacedKey(role.Namespace, role.Name)] = role
+		}
+		for i := range s.ClusterRoles {
+			idx.clusterRoles[s.ClusterRoles[i].Name] = &s.ClusterRoles[i]
+		}
+
+		for _, d := range s.Deployments {
+			idx.owners[d.UID] = d.ObjectMeta
+		}
+		for _, rs := range s.ReplicaSets {
+			idx.owners[rs.UID] = rs.ObjectMeta
+		}
+		for _, sts := range s.StatefulSets {
+			idx.owners[sts.UID] = sts.ObjectMeta
+		}
+		for _, ds := range s.DaemonSets {
+			idx.owners[ds.UID] = ds.ObjectMeta
+		}
+		for _, job := range s.Jobs {
+			idx.owners[job.UID] = job.ObjectMeta
+		}
+		for _, cj := range s.CronJobs {
+			idx.owners[cj.UID] = cj.ObjectMeta
+		}
+
+		s.idx = idx
+	})
+	return s.idx
+}
+
+// baselineClusterRoles are granted to every identity by the default bootstrap
+// policy and only allow discovery, so they do not count as API access
+var baselineClusterRoles = map[string]bool{
+	"system:basic-user":                       true,
+	"system:discovery":                        true,
+	"system:public-info-viewer":               true,
+	"system:service-account-issuer-discovery": true,
+}
+
+// RoleGrant is a role granted to a subject through a binding
+type RoleGrant struct {
+	// Binding names the binding, e.g. "RoleBinding/app-reader"
+	Binding string
+	// Namespace is where the grant applies, empty for ClusterRoleBindings
+	Namespace string
+	RoleRef   rbacv1.RoleRef
+}
+
+// addGrants records the service accounts a binding grants permissions to
+func (idx *snapshotIndex) addGrants(grant RoleGrant, subjects []rbacv1.Subject) {
+	if grant.RoleRef.Kind == "ClusterRole" && baselineClusterRoles[grant.RoleRef.Name] {
+		return
+	}
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			namespace := subject.Namespace
+			if namespace == "" {
+				namespace = grant.Namespace
+			}
+			key := namespacedKey(namespace, subject.Name)
+			idx.grantsBySubject[key] = append(idx.grantsBySubject[key], grant)
+		case rbacv1.GroupKind:
+			if subject.Name == "system:serviceaccounts" {
+				idx.grantsBySubject["*"] = append(idx.grantsBySubject["*"], grant)
+			} else if namespace, ok := strings.CutPrefix(subject.Name, "system:serviceaccounts:"); ok {
+				key := namespacedKey(namespace, "*")
+				idx.grantsBySubject[key] = append(idx.grantsBySubject[key], grant)
+			}
+		}
+	}
+}
+
+// PodsInNamespace returns the pods in a namespace
+func (s *Snapshot) PodsInNamespace(namespace string) []*corev1.Pod {
+	return s.index().podsByNamespace[namespace]
+}
+
+// PodsMatchingLabels returns the pods in a namespace carrying every given label
+func (s *Snapshot) PodsMatchingLabels(namespace string, match map[string]string) []*corev1.Pod {
+	if len(match) == 0 {
+		return nil
+	}
+
+	// Start from the smallest candidate set and filter the rest
+	idx := s.index()
+	var candidates []*corev1.Pod
+	first := true
+	for k, v := range match {
+		pods := idx.podsByLabel[namespacedKey(namespace, labelKey(k, v))]
+		if first || len(pods) < len(candidates) {
+			candidates = pods
+			first = false
+		}
+	}
+
+	selector := labels.SelectorFromSet(match)
+	var pods []*corev1.Pod
+	for _, pod := range candidates {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+// PodsOwnedBy returns the pods whose owner references include the given UID
+func (s *Snapshot) PodsOwnedBy(uid types.UID) []*corev1.Pod {
+	return s.index().podsByOwner[uid]
+}
+
+// NamespaceByName looks up a namespace by name
+func (s *Snapshot) NamespaceByName(name string) *corev1.Namespace {
+	return s.index().namespaces[name]
+}
+
+// ServiceAccount looks up a service account by namespace and name
+func (s *Snapshot) ServiceAccount(namespace, name string) *corev1.ServiceAccount {
+	return s.index().serviceAccounts[namespacedKey(namespace, name)]
+}
+
+// Service looks up a service by namespace and name
+func (s *Snapshot) Service(namespace, name string) *corev1.Service {
+	for _, svc := range s.index().servicesByNS[namespace] {
+		if svc.Name == name {
+			return svc
+		}
+	}
+	return nil
+}
+
+// IngressBackendServices returns the names of the services an Ingress routes
+// to, each listed once
+func IngressBackendServices(ing *networkingv1.Ingress) []string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(backend *networkingv1.IngressBackend) {
+		if backend == nil || backend.Service == nil || seen[backend.Service.Name] {
+			return
+		}
+		seen[backend.Service.Name] = true
+		names = append(names, backend.Service.Name)
+	}
+
+	add(ing.Spec.DefaultBackend)
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			add(&rule.HTTP.Paths[i].Backend)
+		}
+	}
+	return names
+}
+
+// IngressesForService returns the Ingresses routing traffic to a service
+func (s *Snapshot) IngressesForService(namespace, name string) []*networkingv1.Ingress {
+	return s.index().ingressesByService[namespacedKey(namespace, name)]
+}
+
+// ResourceQuotasInNamespace returns the resource quotas of a namespace
+func (s *Snapshot) ResourceQuotasInNamespace(namespace string) []*corev1.ResourceQuota {
+	return s.index().quotasByNS[namespace]
+}
+
+// LimitRangesInNamespace returns the limit ranges of a namespace
+func (s *Snapshot) LimitRangesInNamespace(namespace string) []*corev1.LimitRange {
+	return s.index().limitRangesByNS[namespace]
+}
+
+// NetworkPoliciesInNamespace returns the network policies of a namespace
+func (s *Snapshot) NetworkPoliciesInNamespace(namespace string) []*networkingv1.NetworkPolicy {
+	return s.index().netpolsByNS[namespace]
+}
+
+// ServicesSelecting returns the services whose selector matches the pod
+func (s *Snapshot) ServicesSelecting(pod *corev1.Pod) []*corev1.Service {
+	return s.ServicesMatching(pod.Namespace, pod.Labels)
+}
+
+// ServicesMatching returns the services in a namespace whose selector
+// matches the given pod labels, such as those of a pod template
+func (s *Snapshot) ServicesMatching(namespace string, podLabels map[string]string) []*corev1.Service {
+	var services []*corev1.Service
+	for _, svc := range s.index().servicesByNS[namespace] {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(podLabels)) {
+			services = append(services, svc)
+		}
+	}
+	return services
+}
+
+// PodDisruptionBudgetsMatching returns the disruption budgets in a namespace
+// whose selector matches the given pod labels
+func (s *Snapshot) PodDisruptionBudgetsMatching(namespace string, podLabels map[string]string) []*policyv1.PodDisruptionBudget {
+	var budgets []*policyv1.PodDisruptionBudget
+	for _, pdb := range s.index().pdbsByNS[namespace] {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(podLabels)) {
+			budgets = append(budgets, pdb)
+		}
+	}
+	return budgets
+}
+
+// NetworkPoliciesSelecting returns the network policies whose podSelector matches the pod
+func (s *Snapshot) NetworkPoliciesSelecting(pod *corev1.Pod) []*networkingv1.NetworkPolicy {
+	var policies []*networkingv1.NetworkPolicy
+	for _, np := range s.index().netpolsByNS[pod.Namespace] {
+		selector, err := metav1.LabelSelectorAsSelector(&np.Spec.PodSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			policies = append(policies, np)
+		}
+	}
+	return policies
+}
+
+// ServiceAccountGrants returns the roles bound to a service account, directly
+// or through the system:serviceaccounts groups. Baseline discovery roles are
+// left out.
+func (s *Snapshot) ServiceAccountGrants(namespace, name string) []RoleGrant {
+	grants := s.index().grantsBySubject
+	var all []RoleGrant
+	all = append(all, grants[namespacedKey(namespace, name)]...)
+	all = append(all, grants[namespacedKey(namespace, "*")]...)
+	all = append(all, grants["*"]...)
+	return all
+}
+
+// ServiceAccountBound reports whether any RoleBinding or ClusterRoleBinding
+// grants permissions beyond discovery to the service account
+func (s *Snapshot) ServiceAccountBound(namespace, name string) bool {
+	return len(s.ServiceAccountGrants(namespace, name)) > 0
+}
+
+// RulesFor returns the policy rules of the role a grant refers to, or nil
+// when the role was not captured
+func (s *Snapshot) RulesFor(grant RoleGrant) []rbacv1.PolicyRule {
+	idx := s.index()
+	switch grant.RoleRef.Kind {
+	case "ClusterRole":
+		if role := idx.clusterRoles[grant.RoleRef.Name]; role != nil {
+			return role.Rules
+		}
+	case "Role":
+		if role := idx.roles[namespacedKey(grant.Namespace, grant.RoleRef.Name)]; role != nil {
+			return role.Rules
+		}
+	}
+	return nil
+}
+
+// WorkloadFor resolves a pod to its top-level controller, following
+// ReplicaSet -> Deployment and Job -> CronJob chains. Pods without a known
+// controller are reported as themselves. An owner cycle stops at the last
+// owner before it repeats.
+func (s *Snapshot) WorkloadFor(pod *corev1.Pod) WorkloadRef {
+	ref := WorkloadRef{Kind: "Pod", Name: pod.Name, Namespace: pod.Namespace}
+
+	owners := s.index().owners
+	visited := map[types.UID]bool{}
+	controller := metav1.GetControllerOf(pod)
+	for controller != nil && !visited[controller.UID] {
+		visited[controller.UID] = true
+		ref.Kind = controller.Kind
+		ref.Name = controller.Name
+
+		meta, ok := owners[controller.UID]
+		if !ok {
+			break
+		}
+		controller = metav1.GetControllerOf(&meta)
+	}
+	return ref
+}
@@ -0,0 +1,182 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func controllerRef(kind, name, uid string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, UID: types.UID(uid), Controller: &controller}}
+}
+
+func TestWorkloadForFollowsOwnerChain(t *testing.T) {
+	snap := &Snapshot{
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "d1"},
+		}},
+		ReplicaSets: []appsv1.ReplicaSet{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-5d9", Namespace: "shop", UID: "rs1", OwnerReferences: controllerRef("Deployment", "web", "d1")},
+		}},
+		Pods: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-5d9-abc", Namespace: "shop", OwnerReferences: controllerRef("ReplicaSet", "web-5d9", "rs1")}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "shop"}},
+		},
+	}
+
+	if ref := snap.WorkloadFor(&snap.Pods[0]); ref.Kind != "Deployment" || ref.Name != "web" {
+		t.Errorf("expected Deployment/web, got %s/%s", ref.Kind, ref.Name)
+	}
+	if ref := snap.WorkloadFor(&snap.Pods[1]); ref.Kind != "Pod" || ref.Name != "debug" {
+		t.Errorf("expected bare pod to resolve to itself, got %s/%s", ref.Kind, ref.Name)
+	}
+	if pods := snap.PodsOwnedBy("rs1"); len(pods) != 1 {
+		t.Errorf("expected 1 pod owned by rs1, got %d", len(pods))
+	}
+}
+
+func TestWorkloadForStopsOnOwnerCycle(t *testing.T) {
+	// A corrupt archive or a misbehaving controller can produce owners
+	// that own each other
+	snap := &Snapshot{
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "d1", OwnerReferences: controllerRef("ReplicaSet", "web-5d9", "rs1")},
+		}},
+		ReplicaSets: []appsv1.ReplicaSet{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-5d9", Namespace: "shop", UID: "rs1", OwnerReferences: controllerRef("Deployment", "web", "d1")},
+		}},
+		Pods: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-5d9-abc", Namespace: "shop", OwnerReferences: controllerRef("ReplicaSet", "web-5d9", "rs1")}},
+		},
+	}
+
+	if ref := snap.WorkloadFor(&snap.Pods[0]); ref.Kind != "Deployment" || ref.Name != "web" {
+		t.Errorf("expected the chain to stop at Deployment/web, got %s/%s", ref.Kind, ref.Name)
+	}
+}
+
+func TestPodsMatchingLabelsAndServices(t *testing.T) {
+	snap := &Snapshot{
+		Pods: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "shop", Labels: map[string]string{"app": "web", "tier": "front"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "shop", Labels: map[string]string{"app": "web"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "other", Labels: map[string]string{"app": "web", "tier": "front"}}},
+		},
+		Services: []corev1.Service{{
+			ObjectMeta: metav1.ObjectMeta{Name: "front", Namespace: "shop"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"tier": "front"}},
+		}},
+	}
+
+	pods := snap.PodsMatchingLabels("shop", map[string]string{"app": "web", "tier": "front"})
+	if len(pods) != 1 || pods[0].Name != "a" {
+		t.Errorf("expected only pod a to match, got %v", pods)
+	}
+
+	if svcs := snap.ServicesSelecting(&snap.Pods[0]); len(svcs) != 1 {
+		t.Errorf("expected pod a to be selected by one service, got %d", len(svcs))
+	}
+	if svcs := snap.ServicesSelecting(&snap.Pods[2]); len(svcs) != 0 {
+		t.Errorf("expected services not to match across namespaces, got %d", len(svcs))
+	}
+}
+
+func TestCollectListsEachResourceOnce(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "shop"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "shop"}},
+	)
+	calls := map[string]int{}
+	clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls[action.GetResource().Resource]++
+		return false, nil, nil
+	})
+
+	client := &Client{Clientset: clientset}
+	resources := []Resource{ResourcePods, ResourceServiceAccounts, ResourcePods}
+	snap, err := Collect(context.Background(), client, "", resources, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls["pods"] != 1 || calls["serviceaccounts"] != 1 {
+		t.Errorf("expected a single list call per resource, got %v", calls)
+	}
+	if !snap.Has(ResourcePods) || !snap.Has(ResourceServiceAccounts) || snap.Has(ResourceServices) {
+		t.Errorf("unexpected collected resources: %v", snap.Collected)
+	}
+	if len(snap.Pods) != 1 || len(snap.ServiceAccounts) != 1 {
+		t.Errorf("expected one pod and one service account, got %d and %d", len(snap.Pods), len(snap.ServiceAccounts))
+	}
+}
+
+func TestCollectRecordsForbiddenResources(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("denied"))
+	})
+
+	client := &Client{Clientset: clientset}
+	snap, err := Collect(context.Background(), client, "shop", []Resource{ResourcePods, ResourceSecrets}, 2)
+	if err == nil {
+		t.Fatal("expected an error for the forbidden resource")
+	}
+
+	if !snap.Has(ResourcePods) || snap.Has(ResourceSecrets) {
+		t.Errorf("unexpected collected resources: %v", snap.Collected)
+	}
+	collectErr := snap.ErrorFor(ResourceSecrets)
+	if collectErr == nil || collectErr.Reason != ReasonForbidden || collectErr.Namespace != "shop" {
+		t.Errorf("expected a forbidden error for secrets in shop, got %+v", collectErr)
+	}
+}
+
+func TestCollectSkipsMissingGatewayAPI(t *testing.T) {
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"name": "public", "namespace": "edge"},
+	}}
+	gatewayGVR := schema.GroupVersionResource{Group: ResourceGateways.Group, Version: ResourceGateways.Version, Resource: ResourceGateways.Resource}
+	grantGVR := schema.GroupVersionResource{Group: ResourceReferenceGrants.Group, Version: ResourceReferenceGrants.Version, Resource: ResourceReferenceGrants.Resource}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gatewayGVR: "GatewayList",
+		grantGVR:   "ReferenceGrantList",
+	})
+	if _, err := dynamicClient.Resource(gatewayGVR).Namespace("edge").Create(context.Background(), gateway, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// The API server answers 404 for resources whose CRD is not installed
+	dynamicClient.PrependReactor("list", "referencegrants", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(grantGVR.GroupResource(), "")
+	})
+
+	client := &Client{Clientset: fake.NewClientset(), Dynamic: dynamicClient}
+	snap, err := Collect(context.Background(), client, "", []Resource{ResourceGateways, ResourceReferenceGrants}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !snap.Has(ResourceGateways) || !snap.Has(ResourceReferenceGrants) {
+		t.Errorf("expected both resources to be collected, got %v", snap.Collected)
+	}
+	if len(snap.Gateways) != 1 || snap.Gateways[0].GetName() != "public" {
+		t.Errorf("expected the public gateway, got %+v", snap.Gateways)
+	}
+	if len(snap.ReferenceGrants) != 0 {
+		t.Errorf("expected no reference grants, got %d", len(snap.ReferenceGrants))
+	}
+}
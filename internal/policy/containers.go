@@ -0,0 +1,45 @@
+package policy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ContainerType tells which part of the pod spec a container comes from
+type ContainerType string
+
+const (
+	ContainerTypeRegular ContainerType = "container"
+	ContainerTypeInit    ContainerType = "init"
+	// ContainerTypeSidecar marks init containers with 'restartPolicy: Always',
+	// which keep running next to the regular containers
+	ContainerTypeSidecar   ContainerType = "sidecar"
+	ContainerTypeEphemeral ContainerType = "ephemeral"
+)
+
+// podContainer is a container of any type together with its type
+type podContainer struct {
+	corev1.Container
+	Type ContainerType
+}
+
+// podContainers returns every container of a pod spec: init containers
+// (including native sidecars), regular containers and ephemeral debug
+// containers, in that order
+func podContainers(spec *corev1.PodSpec) []podContainer {
+	containers := make([]podContainer, 0, len(spec.InitContainers)+len(spec.Containers)+len(spec.EphemeralContainers))
+	for _, container := range spec.InitContainers {
+		containerType := ContainerTypeInit
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			containerType = ContainerTypeSidecar
+		}
+		containers = append(containers, podContainer{Container: container, Type: containerType})
+	}
+	for _, container := range spec.Containers {
+		containers = append(containers, podContainer{Container: container, Type: ContainerTypeRegular})
+	}
+	for _, container := range spec.EphemeralContainers {
+		// Ephemeral containers share the Container fields under another name
+		containers = append(containers, podContainer{Container: corev1.Container(container.EphemeralContainerCommon), Type: ContainerTypeEphemeral})
+	}
+	return containers
+}
@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodScannerAuditsEveryContainerType(t *testing.T) {
+	privileged := true
+	always := corev1.ContainerRestartPolicyAlways
+	sc := &corev1.SecurityContext{Privileged: &privileged}
+
+	snap := &k8s.Snapshot{Pods: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{Name: "setup", SecurityContext: sc},
+				{Name: "proxy", SecurityContext: sc, RestartPolicy: &always},
+			},
+			Containers: []corev1.Container{{Name: "app", SecurityContext: sc}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", SecurityContext: sc},
+			}},
+		},
+	}}}
+
+	rec := NewRecorder()
+	if err := (&PodScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]ContainerType{}
+	for _, issue := range rec.Issues() {
+		if issue.ID == "HK-001" {
+			got[issue.Container] = issue.ContainerType
+		}
+	}
+
+	want := map[string]ContainerType{
+		"setup":    ContainerTypeInit,
+		"proxy":    ContainerTypeSidecar,
+		"app":      ContainerTypeRegular,
+		"debugger": ContainerTypeEphemeral,
+	}
+	for name, containerType := range want {
+		if got[name] != containerType {
+			t.Errorf("container %s: expected privileged issue with type %q, got %q", name, containerType, got[name])
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Control plane components as named by their binaries and kubeadm's
+// "component" label
+const (
+	componentAPIServer         = "kube-apiserver"
+	componentControllerManager = "kube-controller-manager"
+	componentScheduler         = "kube-scheduler"
+	componentEtcd              = "etcd"
+)
+
+// requiredAdmissionPlugins must be enabled on the API server. The plugins in
+// defaultAdmissionPlugins are on by default and must stay on.
+var (
+	requiredAdmissionPlugins = []string{"NodeRestriction"}
+	defaultAdmissionPlugins  = []string{"NamespaceLifecycle", "ServiceAccount"}
+)
+
+// auditRetentionFlags bound how much audit history the API server keeps
+var auditRetentionFlags = []string{"--audit-log-maxage", "--audit-log-maxbackup", "--audit-log-maxsize"}
+
+// controlPlaneIssue builds a finding for the component being checked
+type controlPlaneIssue func(id, title, description string, severity Severity, remediation string) Issue
+
+// componentFlags holds the command-line flags of a control plane container
+type componentFlags map[string]string
+
+// parseFlags reads "--name=value", "--name value" and bare "--name" flags
+// from a container's command and args
+func parseFlags(container corev1.Container) componentFlags {
+	flags := componentFlags{}
+	args := append(append([]string{}, container.Command...), container.Args...)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			flags[name] = value
+			continue
+		}
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			flags[arg] = args[i+1]
+			i++
+			continue
+		}
+		flags[arg] = "true"
+	}
+	return flags
+}
+
+// get returns a flag value and whether the flag was set
+func (f componentFlags) get(name string) (string, bool) {
+	value, ok := f[name]
+	return value, ok
+}
+
+// list splits a comma-separated flag value
+func (f componentFlags) list(name string) []string {
+	var values []string
+	for _, value := range strings.Split(f[name], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// controlPlaneComponent identifies the control plane component a container
+// runs, or returns an empty string
+func controlPlaneComponent(container corev1.Container) string {
+	if len(container.Command) == 0 {
+		return ""
+	}
+	switch name := path.Base(container.Command[0]); name {
+	case componentAPIServer, componentControllerManager, componentScheduler, componentEtcd:
+		return name
+	}
+	return ""
+}
+
+// ControlPlaneScanner checks CIS control plane items against the flags of
+// the API server, controller manager, scheduler and etcd static pods. Managed
+// clusters do not expose these pods, so the scanner finds nothing to check.
+type ControlPlaneScanner struct{}
+
+// Name returns the scanner identifier
+func (s *ControlPlaneScanner) Name() string {
+	return "control-plane"
+}
+
+// Resources returns the resources the control plane scanner reads
+func (s *ControlPlaneScanner) Resources() []k8s.Resource {
+	return []k8s.Resource{k8s.ResourcePods}
+}
+
+// Scan runs the control plane checks
+func (s *ControlPlaneScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	for _, pod := range snap.PodsInNamespace("kube-system") {
+		for _, container := range pod.Spec.Containers {
+			component := controlPlaneComponent(container)
+			if component == "" {
+				continue
+			}
+			rec.Scanned("Pod", pod.Namespace, pod.Name)
+			s.checkComponent(rec, pod, component, parseFlags(container))
+		}
+	}
+	return nil
+}
+
+func (s *ControlPlaneScanner) checkComponent(rec *Recorder, pod *corev1.Pod, component string, flags componentFlags) {
+	issue := controlPlaneIssue(func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("%s in pod %s %s", component, pod.Name, description),
+			Severity:    severity,
+			Resource:    "Pod/" + pod.Name,
+			Namespace:   pod.Namespace,
+			Remediation: remediation + " Edit the static pod manifest in /etc/kubernetes/manifests on each control plane node.",
+			Category:    CategoryControlPlane,
+			Container:   component,
+		}
+	})
+
+	// Check: profiling exposes runtime internals and is on by default
+	if component != componentEtcd {
+		if value, _ := flags.get("--profiling"); value != "false" {
+			rec.Fail(issue("HK-059", "Control Plane Profiling Enabled",
+				"has profiling enabled",
+				SeverityLow, "Set '--profiling=false'."))
+		} else {
+			rec.Pass("HK-059")
+		}
+	}
+
+	switch component {
+	case componentAPIServer:
+		s.checkAPIServer(rec, flags, issue)
+	case componentControllerManager, componentScheduler:
+		// Check: the unauthenticated HTTP port removed in Kubernetes 1.23
+		if value, ok := flags.get("--port"); ok && value != "0" {
+			rec.Fail(issue("HK-060", "Insecure Control Plane Port",
+				fmt.Sprintf("serves plain HTTP without authentication on port %s", value),
+				SeverityHigh, "Set '--port=0' or upgrade to a release without the insecure port."))
+		} else {
+			rec.Pass("HK-060")
+		}
+	case componentEtcd:
+		s.checkEtcd(rec, flags, issue)
+	}
+}
+
+func (s *ControlPlaneScanner) checkAPIServer(rec *Recorder, flags componentFlags, issue controlPlaneIssue) {
+	// Check: anonymous requests are allowed unless turned off
+	if value, _ := flags.get("--anonymous-auth"); value != "false" {
+		rec.Fail(issue("HK-056", "API Server Anonymous Authentication Enabled",
+			"accepts anonymous requests",
+			SeverityMedium, "Set '--anonymous-auth=false', or restrict anonymous access to health endpoints with an authentication configuration."))
+	} else {
+		rec.Pass("HK-056")
+	}
+
+	// Check: audit logging and its retention
+	_, logPath := flags.get("--audit-log-path")
+	_, policyFile := flags.get("--audit-policy-file")
+	var retention []string
+	for _, name := range auditRetentionFlags {
+		if _, ok := flags.get(name); !ok {
+			retention = append(retention, name)
+		}
+	}
+	switch {
+	case !logPath || !policyFile:
+		rec.Fail(issue("HK-057", "API Server Audit Logging Disabled",
+			"does not write an audit log, so API activity cannot be investigated",
+			SeverityMedium, "Set '--audit-policy-file' and '--audit-log-path'."))
+	case len(retention) > 0:
+		rec.Fail(issue("HK-057", "API Server Audit Log Retention Not Set",
+			fmt.Sprintf("writes an audit log without %s", strings.Join(retention, ", ")),
+			SeverityLow, "Set '--audit-log-maxage', '--audit-log-maxbackup' and '--audit-log-maxsize'."))
+	default:
+		rec.Pass("HK-057")
+	}
+
+	// Check: Secrets stored in etcd in plain text
+	if _, ok := flags.get("--encryption-provider-config"); !ok {
+		rec.Fail(issue("HK-058", "Secrets Not Encrypted At Rest",
+			"has no '--encryption-provider-config', so Secrets are stored unencrypted in etcd",
+			SeverityHigh, "Create an EncryptionConfiguration with a KMS or aescbc/secretbox provider and pass it with '--encryption-provider-config'."))
+	} else {
+		rec.Pass("HK-058")
+	}
+
+	// Check: the unauthenticated HTTP port removed in Kubernetes 1.20
+	if value, ok := flags.get("--insecure-port"); ok && value != "0" {
+		rec.Fail(issue("HK-060", "Insecure Control Plane Port",
+			fmt.Sprintf("serves plain HTTP without authentication on port %s", value),
+			SeverityCritical, "Set '--insecure-port=0' or upgrade to a release without the insecure port."))
+	} else {
+		rec.Pass("HK-060")
+	}
+
+	// Check: admission plugins that enforce node isolation and namespace rules
+	enabled, disabled := flags.list("--enable-admission-plugins"), flags.list("--disable-admission-plugins")
+	var problems []string
+	if slices.Contains(enabled, "AlwaysAdmit") {
+		problems = append(problems, "AlwaysAdmit is enabled")
+	}
+	for _, plugin := range requiredAdmissionPlugins {
+		if !slices.Contains(enabled, plugin) {
+			problems = append(problems, plugin+" is not enabled")
+		}
+	}
+	for _, plugin := range defaultAdmissionPlugins {
+		if slices.Contains(disabled, plugin) {
+			problems = append(problems, plugin+" is disabled")
+		}
+	}
+	if len(problems) > 0 {
+		rec.Fail(issue("HK-061", "API Server Admission Plugins Misconfigured",
+			strings.Join(problems, ", "),
+			SeverityMedium, "Enable NodeRestriction, remove AlwaysAdmit and keep the default admission plugins enabled."))
+	} else {
+		rec.Pass("HK-061")
+	}
+
+	// Check: authorization mode
+	if slices.Contains(flags.list("--authorization-mode"), "AlwaysAllow") {
+		rec.Fail(issue("HK-063", "API Server Authorization AlwaysAllow",
+			"authorizes every authenticated request",
+			SeverityCritical, "Set '--authorization-mode=Node,RBAC'."))
+	} else {
+		rec.Pass("HK-063")
+	}
+}
+
+func (s *ControlPlaneScanner) checkEtcd(rec *Recorder, flags componentFlags, issue controlPlaneIssue) {
+	// Check: plain HTTP client listeners
+	var plaintext []string
+	for _, url := range flags.list("--listen-client-urls") {
+		if strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "http://127.0.0.1") && !strings.HasPrefix(url, "http://localhost") {
+			plaintext = append(plaintext, url)
+		}
+	}
+	if len(plaintext) > 0 {
+		rec.Fail(issue("HK-060", "Insecure Control Plane Port",
+			fmt.Sprintf("accepts plain HTTP clients on %s", strings.Join(plaintext, ", ")),
+			SeverityCritical, "Serve etcd clients over HTTPS only."))
+	} else {
+		rec.Pass("HK-060")
+	}
+
+	// Check: client and peer certificate authentication
+	var problems []string
+	for _, name := range []string{"--client-cert-auth", "--peer-client-cert-auth"} {
+		if value, _ := flags.get(name); value != "true" {
+			problems = append(problems, name+" is not true")
+		}
+	}
+	for _, name := range []string{"--auto-tls", "--peer-auto-tls"} {
+		if value, _ := flags.get(name); value == "true" {
+			problems = append(problems, name+" uses self-signed certificates")
+		}
+	}
+	if len(problems) > 0 {
+		rec.Fail(issue("HK-062", "etcd Certificate Authentication Disabled",
+			fmt.Sprintf("does not enforce certificate authentication: %s", strings.Join(problems, ", ")),
+			SeverityHigh, "Set '--client-cert-auth=true' and '--peer-client-cert-auth=true' and use certificates signed by the etcd CA instead of auto TLS."))
+	} else {
+		rec.Pass("HK-062")
+	}
+}
@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseFlags(t *testing.T) {
+	flags := parseFlags(corev1.Container{
+		Command: []string{"kube-apiserver", "--anonymous-auth=false", "--audit-log-path", "/var/log/audit.log", "--v=2"},
+		Args:    []string{"--allow-privileged"},
+	})
+
+	want := map[string]string{
+		"--anonymous-auth":   "false",
+		"--audit-log-path":   "/var/log/audit.log",
+		"--v":                "2",
+		"--allow-privileged": "true",
+	}
+	for name, value := range want {
+		if got, _ := flags.get(name); got != value {
+			t.Errorf("%s: expected %q, got %q", name, value, got)
+		}
+	}
+}
+
+func TestControlPlaneScanner(t *testing.T) {
+	staticPod := func(name string, command ...string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: command[0], Command: command}}},
+		}
+	}
+
+	snap := &k8s.Snapshot{Pods: []corev1.Pod{
+		staticPod("kube-apiserver-cp1", "kube-apiserver",
+			"--authorization-mode=Node,RBAC",
+			"--enable-admission-plugins=NodeRestriction",
+			"--audit-policy-file=/etc/kubernetes/audit.yaml",
+			"--audit-log-path=/var/log/kubernetes/audit.log",
+		),
+		staticPod("kube-scheduler-cp1", "kube-scheduler", "--profiling=false", "--port=10251"),
+		staticPod("etcd-cp1", "etcd",
+			"--client-cert-auth=true",
+			"--peer-client-cert-auth=true",
+			"--listen-client-urls=https://10.0.0.10:2379,http://127.0.0.1:2379",
+		),
+		// Look-alike outside kube-system is not part of the control plane
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "etcd-test", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "etcd", Command: []string{"etcd"}}}},
+		},
+	}}
+
+	rec := NewRecorder()
+	if err := (&ControlPlaneScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{
+		"HK-056": 1, "HK-057": 1, "HK-058": 1, "HK-059": 1, "HK-060": 1,
+		"HK-061": 0, "HK-062": 0, "HK-063": 0,
+	})
+
+	for _, issue := range rec.Issues() {
+		switch issue.ID {
+		case "HK-057":
+			if issue.Severity != SeverityLow {
+				t.Errorf("expected missing audit retention to be %s, got %s", SeverityLow, issue.Severity)
+			}
+		case "HK-060":
+			if issue.Resource != "Pod/kube-scheduler-cp1" {
+				t.Errorf("expected the scheduler's insecure port to be reported, got %s", issue.Resource)
+			}
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// apiDeprecation is a served API version that upstream Kubernetes deprecated
+// and later removed
+type apiDeprecation struct {
+	APIVersion string
+	// Kinds limits the entry to some kinds of the API version; nil matches all
+	Kinds        []string
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+// apiDeprecations follows the upstream deprecated API migration guide
+var apiDeprecations = []apiDeprecation{
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"Deployment", "DaemonSet", "ReplicaSet"}, DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"NetworkPolicy"}, DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"PodSecurityPolicy"}, DeprecatedIn: "1.11", RemovedIn: "1.16", Replacement: "policy/v1beta1"},
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"Ingress"}, DeprecatedIn: "1.14", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "apps/v1beta1", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "apps/v1beta2", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "networking.k8s.io/v1beta1", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "admissionregistration.k8s.io/v1beta1", DeprecatedIn: "1.16", RemovedIn: "1.22", Replacement: "admissionregistration.k8s.io/v1"},
+	{APIVersion: "apiextensions.k8s.io/v1beta1", DeprecatedIn: "1.16", RemovedIn: "1.22", Replacement: "apiextensions.k8s.io/v1"},
+	{APIVersion: "apiregistration.k8s.io/v1beta1", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "apiregistration.k8s.io/v1"},
+	{APIVersion: "certificates.k8s.io/v1beta1", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "certificates.k8s.io/v1"},
+	{APIVersion: "coordination.k8s.io/v1beta1", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "coordination.k8s.io/v1"},
+	{APIVersion: "scheduling.k8s.io/v1beta1", DeprecatedIn: "1.14", RemovedIn: "1.22", Replacement: "scheduling.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kinds: []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "storage.k8s.io/v1"},
+	{APIVersion: "batch/v1beta1", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "batch/v1"},
+	{APIVersion: "policy/v1beta1", Kinds: []string{"PodDisruptionBudget"}, DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "policy/v1"},
+	{APIVersion: "policy/v1beta1", Kinds: []string{"PodSecurityPolicy"}, DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "Pod Security Admission"},
+	{APIVersion: "discovery.k8s.io/v1beta1", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "discovery.k8s.io/v1"},
+	{APIVersion: "events.k8s.io/v1beta1", DeprecatedIn: "1.19", RemovedIn: "1.25", Replacement: "events.k8s.io/v1"},
+	{APIVersion: "node.k8s.io/v1beta1", DeprecatedIn: "1.20", RemovedIn: "1.25", Replacement: "node.k8s.io/v1"},
+	{APIVersion: "autoscaling/v2beta1", DeprecatedIn: "1.22", RemovedIn: "1.25", Replacement: "autoscaling/v2"},
+	{APIVersion: "autoscaling/v2beta2", DeprecatedIn: "1.23", RemovedIn: "1.26", Replacement: "autoscaling/v2"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", DeprecatedIn: "1.23", RemovedIn: "1.26", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kinds: []string{"CSIStorageCapacity"}, DeprecatedIn: "1.24", RemovedIn: "1.27", Replacement: "storage.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", DeprecatedIn: "1.26", RemovedIn: "1.29", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", DeprecatedIn: "1.29", RemovedIn: "1.32", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// findDeprecation returns the table entry for an apiVersion and kind
+func findDeprecation(apiVersion, kind string) (apiDeprecation, bool) {
+	for _, d := range apiDeprecations {
+		if d.APIVersion == apiVersion && (d.Kinds == nil || slices.Contains(d.Kinds, kind)) {
+			return d, true
+		}
+	}
+	return apiDeprecation{}, false
+}
+
+// liveObject is a collected object whose metadata records the apiVersions
+// its clients used
+type liveObject struct {
+	Kind string
+	Meta metav1.Object
+}
+
+// appendLive adds every item of a typed list to objects
+func appendLive[T any, P interface {
+	*T
+	metav1.Object
+}](objects []liveObject, kind string, items []T) []liveObject {
+	for i := range items {
+		objects = append(objects, liveObject{Kind: kind, Meta: P(&items[i])})
+	}
+	return objects
+}
+
+// liveObjects returns the collected kinds that have been served from
+// deprecated API versions
+func liveObjects(snap *k8s.Snapshot) []liveObject {
+	var objects []liveObject
+	objects = appendLive(objects, "Deployment", snap.Deployments)
+	objects = appendLive(objects, "ReplicaSet", snap.ReplicaSets)
+	objects = appendLive(objects, "StatefulSet", snap.StatefulSets)
+	objects = appendLive(objects, "DaemonSet", snap.DaemonSets)
+	objects = appendLive(objects, "CronJob", snap.CronJobs)
+	objects = appendLive(objects, "Ingress", snap.Ingresses)
+	objects = appendLive(objects, "NetworkPolicy", snap.NetworkPolicies)
+	objects = appendLive(objects, "PodDisruptionBudget", snap.PodDisruptionBudgets)
+	objects = appendLive(objects, "Role", snap.Roles)
+	objects = appendLive(objects, "ClusterRole", snap.ClusterRoles)
+	objects = appendLive(objects, "RoleBinding", snap.RoleBindings)
+	objects = appendLive(objects, "ClusterRoleBinding", snap.ClusterRoleBindings)
+	objects = appendLive(objects, "ValidatingWebhookConfiguration", snap.ValidatingWebhookConfigurations)
+	objects = appendLive(objects, "MutatingWebhookConfiguration", snap.MutatingWebhookConfigurations)
+	return objects
+}
+
+// appliedAPIVersion returns the apiVersion an object was last written with.
+// The API server converts objects to the preferred version when reading, so
+// the version a client used survives only in kubectl's last-applied
+// configuration or in the managed fields entry of the most recent writer.
+// Older managed fields entries only record history and are ignored.
+func appliedAPIVersion(obj metav1.Object) string {
+	if applied, ok := obj.GetAnnotations()[k8s.LastAppliedAnnotation]; ok {
+		var manifest struct {
+			APIVersion string `json:"apiVersion"`
+		}
+		if json.Unmarshal([]byte(applied), &manifest) == nil && manifest.APIVersion != "" {
+			return manifest.APIVersion
+		}
+	}
+
+	var latest *metav1.ManagedFieldsEntry
+	fields := obj.GetManagedFields()
+	for i := range fields {
+		entry := &fields[i]
+		if entry.APIVersion == "" {
+			continue
+		}
+		if latest == nil || (entry.Time != nil && (latest.Time == nil || latest.Time.Before(entry.Time))) {
+			latest = entry
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.APIVersion
+}
+
+// DeprecatedAPIScanner reports objects and manifests using API versions that
+// are deprecated or removed in the target Kubernetes version
+type DeprecatedAPIScanner struct {
+	// TargetVersion is the release being upgraded to; empty uses the server
+	// version, and without either every deprecated API is reported
+	TargetVersion string
+	// Manifests are offline objects checked alongside the cluster
+	Manifests []k8s.Manifest
+}
+
+// Name returns the scanner identifier
+func (s *DeprecatedAPIScanner) Name() string {
+	return "deprecated-apis"
+}
+
+// Resources returns nothing; the scanner checks whatever the other scanners collected
+func (s *DeprecatedAPIScanner) Resources() []k8s.Resource {
+	return nil
+}
+
+// Scan runs the deprecated API checks
+func (s *DeprecatedAPIScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	target := s.TargetVersion
+	if target == "" {
+		target = snap.ServerVersion
+	}
+	var targetVersion *version.Version
+	if target != "" {
+		v, err := version.ParseGeneric(target)
+		if err != nil {
+			return fmt.Errorf("failed to parse target version %q: %w", target, err)
+		}
+		targetVersion = v
+	}
+
+	// Live objects are only upgrade blockers for API versions the server
+	// still serves; anything older is a stale record
+	var serverVersion *version.Version
+	if snap.ServerVersion != "" {
+		v, err := version.ParseGeneric(snap.ServerVersion)
+		if err != nil {
+			return fmt.Errorf("failed to parse server version %q: %w", snap.ServerVersion, err)
+		}
+		serverVersion = v
+	}
+
+	for _, obj := range liveObjects(snap) {
+		rec.Scanned(obj.Kind, obj.Meta.GetNamespace(), obj.Meta.GetName())
+		s.check(rec, targetVersion, serverVersion, obj.Kind, obj.Meta.GetNamespace(), obj.Meta.GetName(), "", appliedAPIVersion(obj.Meta))
+	}
+	for _, m := range s.Manifests {
+		rec.Scanned(m.Kind, m.Namespace, m.Name)
+		s.check(rec, targetVersion, nil, m.Kind, m.Namespace, m.Name, m.Path, m.APIVersion)
+	}
+	return nil
+}
+
+// check reports an object's apiVersion when the target no longer serves it
+// or has deprecated it. served is the version of the cluster the object was
+// read from, nil for manifests; path is set for manifests read from disk.
+func (s *DeprecatedAPIScanner) check(rec *Recorder, target, served *version.Version, kind, namespace, name, path, apiVersion string) {
+	subject := fmt.Sprintf("%s %s", kind, name)
+	if path != "" {
+		subject = fmt.Sprintf("%s %s in %s", kind, name, path)
+	}
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("%s %s", subject, description),
+			Severity:    severity,
+			Namespace:   namespace,
+			Resource:    kind + "/" + name,
+			Remediation: remediation,
+			Category:    CategoryDeprecatedAPIs,
+		}
+	}
+	reached := func(release string) bool {
+		return target == nil || !target.LessThan(version.MustParseGeneric(release))
+	}
+
+	// The server converts stored objects on read, so a live object whose
+	// recorded version the server no longer serves is not an upgrade blocker
+	d, ok := findDeprecation(apiVersion, kind)
+	if !ok || (served != nil && !served.LessThan(version.MustParseGeneric(d.RemovedIn))) {
+		rec.Pass("HK-068")
+		rec.Pass("HK-069")
+		return
+	}
+
+	switch {
+	case reached(d.RemovedIn):
+		// Check: API versions the target release no longer serves
+		rec.Fail(issue("HK-068", "Removed API Version",
+			fmt.Sprintf("uses %s, which was removed in Kubernetes %s", apiVersion, d.RemovedIn),
+			SeverityHigh, fmt.Sprintf("Migrate the manifest to %s; applying it to Kubernetes %s or later fails.", d.Replacement, d.RemovedIn)))
+	case reached(d.DeprecatedIn):
+		// Check: API versions scheduled for removal
+		rec.Fail(issue("HK-069", "Deprecated API Version",
+			fmt.Sprintf("uses %s, deprecated since Kubernetes %s and removed in %s", apiVersion, d.DeprecatedIn, d.RemovedIn),
+			SeverityLow, fmt.Sprintf("Migrate the manifest to %s before upgrading to Kubernetes %s.", d.Replacement, d.RemovedIn)))
+	default:
+		rec.Pass("HK-068")
+		rec.Pass("HK-069")
+	}
+}
@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAppliedAPIVersion(t *testing.T) {
+	older := metav1.NewTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	obj := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+		{Manager: "helm", APIVersion: "apps/v1", Time: &newer},
+		{Manager: "kubectl", APIVersion: "extensions/v1beta1", Time: &older},
+	}}}
+	if got := appliedAPIVersion(obj); got != "apps/v1" {
+		t.Errorf("expected the most recent writer's apps/v1, got %q", got)
+	}
+
+	obj.Annotations = map[string]string{k8s.LastAppliedAnnotation: `{"apiVersion":"apps/v1beta2","kind":"Deployment"}`}
+	if got := appliedAPIVersion(obj); got != "apps/v1beta2" {
+		t.Errorf("expected the last-applied apiVersion, got %q", got)
+	}
+}
+
+func TestDeprecatedAPIScannerIgnoresStaleManagedFields(t *testing.T) {
+	// Written through extensions/v1beta1 long ago; a 1.30 server serves it as apps/v1
+	snap := &k8s.Snapshot{
+		ServerVersion: "v1.30.4",
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "api",
+				Namespace:     "shop",
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", APIVersion: "extensions/v1beta1"}},
+			},
+		}},
+	}
+
+	rec := NewRecorder()
+	if err := (&DeprecatedAPIScanner{TargetVersion: "1.32"}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issues := rec.Issues(); len(issues) != 0 {
+		t.Errorf("expected no issues for an API version the server no longer serves, got %+v", issues)
+	}
+}
+
+func TestDeprecatedAPIScanner(t *testing.T) {
+	snap := &k8s.Snapshot{
+		ServerVersion: "v1.24.17",
+		CronJobs: []batchv1.CronJob{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cleanup",
+				Namespace: "shop",
+				Annotations: map[string]string{
+					k8s.LastAppliedAnnotation: `{"apiVersion":"batch/v1beta1","kind":"CronJob"}`,
+				},
+			},
+		}},
+		// Removed in 1.22, so the 1.24 server already converted it
+		Ingresses: []networkingv1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "shop",
+				Annotations: map[string]string{
+					k8s.LastAppliedAnnotation: `{"apiVersion":"networking.k8s.io/v1beta1","kind":"Ingress"}`,
+				},
+			},
+		}},
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "api",
+				Namespace:     "shop",
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", APIVersion: "apps/v1"}},
+			},
+		}},
+	}
+	manifests := []k8s.Manifest{
+		{Path: "charts/cron.yaml", APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "cleanup", Namespace: "shop"},
+		{Path: "charts/hpa.yaml", APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler", Name: "api", Namespace: "shop"},
+		{Path: "charts/ingress.yaml", APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Name: "web", Namespace: "shop"},
+	}
+
+	scan := func(target string) []Issue {
+		rec := NewRecorder()
+		scanner := &DeprecatedAPIScanner{TargetVersion: target, Manifests: manifests}
+		if err := scanner.Scan(context.Background(), snap, rec); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Issues()
+	}
+
+	// Against the server version only the Ingress manifest is removed
+	assertIssueCounts(t, scan(""), map[string]int{"HK-068": 1, "HK-069": 3})
+
+	// Upgrading to 1.26 removes CronJob batch/v1beta1 and HPA autoscaling/v2beta2
+	issues := scan("1.26")
+	assertIssueCounts(t, issues, map[string]int{"HK-068": 4, "HK-069": 0})
+	for _, issue := range issues {
+		if issue.Resource == "Deployment/api" {
+			t.Errorf("expected apps/v1 Deployment to pass, got %s", issue.Description)
+		}
+	}
+
+	if issues := scan("1.20"); len(issues) != 1 || issues[0].ID != "HK-069" || issues[0].Resource != "Ingress/web" {
+		t.Errorf("expected only the deprecated Ingress manifest for target 1.20, got %+v", issues)
+	}
+}
@@ -3,32 +3,190 @@ package policy
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
 	"github.com/ismailtsdln/HardenaK8s/internal/logger"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/ismailtsdln/HardenaK8s/internal/vuln"
+	corev1 "k8s.io/api/core/v1"
 )
 
+// DefaultWorkers is the number of scanners run concurrently when no value is configured
+const DefaultWorkers = 4
+
+// Options tunes how the engine executes its scanners
+type Options struct {
+	// Workers bounds how many scanners run at the same time
+	Workers int
+	// AllowedRegistries lists trusted image registries or registry/path prefixes
+	AllowedRegistries []string
+	// Vulnerabilities holds offline scanner reports; nil disables the vulnerability scanner
+	Vulnerabilities *vuln.Database
+	// IncludeNodes enables the node and kubelet scanners, which need read
+	// access to nodes and nodes/proxy
+	IncludeNodes bool
+	// IncludeReliability enables the probe, replica and disruption budget checks
+	IncludeReliability bool
+	// TargetVersion is the Kubernetes release deprecated APIs are checked
+	// against; empty uses the server version
+	TargetVersion string
+	// Manifests are offline objects checked for deprecated APIs
+	Manifests []k8s.Manifest
+	// Tenancy assigns namespaces to tenants and lists required namespace labels
+	Tenancy TenancyModel
+	// ExcludeSecrets keeps Secrets from being listed, so the scan needs no
+	// cluster-wide access to secret values; checks that read them are skipped
+	ExcludeSecrets bool
+}
+
 // Engine coordinates the scanning process
 type Engine struct {
 	client   *k8s.Client
 	scanners []Scanner
+	workers  int
+	// node is set on node audit engines and recorded in their results
+	node string
+	// excluded resources are never listed from the cluster
+	excluded map[k8s.Resource]bool
 }
 
 // NewEngine creates a new policy engine
-func NewEngine(client *k8s.Client) *Engine {
+func NewEngine(client *k8s.Client, opts Options) *Engine {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+
+	scanners := []Scanner{
+		&PodScanner{},
+		&ImageScanner{AllowedRegistries: opts.AllowedRegistries},
+		&SecretsScanner{},
+		&ServiceAccountScanner{},
+		&ResourceScanner{},
+		&ExposureScanner{},
+		&TenancyScanner{Model: opts.Tenancy},
+		&GatewayScanner{},
+		&WebhookScanner{},
+		&ControlPlaneScanner{},
+		&VersionScanner{},
+		&DeprecatedAPIScanner{TargetVersion: opts.TargetVersion, Manifests: opts.Manifests}, // Add more scanners here
+	}
+	if opts.Vulnerabilities != nil {
+		scanners = append(scanners, &VulnerabilityScanner{DB: opts.Vulnerabilities})
+	}
+	if opts.IncludeNodes {
+		scanners = append(scanners, &NodeScanner{}, &KubeletScanner{})
+	}
+	if opts.IncludeReliability {
+		scanners = append(scanners, &ReliabilityScanner{})
+	}
+	excluded := map[k8s.Resource]bool{}
+	if opts.ExcludeSecrets {
+		excluded[k8s.ResourceSecrets] = true
+	}
+
 	return &Engine{
-		client: client,
+		client:   client,
+		workers:  workers,
+		scanners: scanners,
+		excluded: excluded,
+	}
+}
+
+// NewNodeAuditEngine creates an engine for 'hardena node-audit', which checks
+// a node's files under root and the kubelet configuration in the snapshot
+// instead of reading the cluster API
+func NewNodeAuditEngine(root, node string) *Engine {
+	return &Engine{
+		workers: DefaultWorkers,
 		scanners: []Scanner{
-			&PodScanner{}, // Add more scanners here
+			&NodeFileScanner{Root: root, Node: node},
+			&KubeletScanner{},
 		},
+		node: node,
+	}
+}
+
+// scanOutcome holds what a single scanner produced
+type scanOutcome struct {
+	rec  *Recorder
+	errs []ScanError
+	// gaps are missing optional inputs; unlike errs they keep the results
+	gaps     []ScanError
+	duration time.Duration
+}
+
+// affectedNamespaces renders a scan scope as the namespaces list of a ScanError
+func affectedNamespaces(namespace string) []string {
+	if namespace == "" {
+		return []string{"*"}
+	}
+	return []string{namespace}
+}
+
+// Resources returns the union of resources read by the registered
+// scanners, required or optional, leaving out excluded resources
+func (e *Engine) Resources() []k8s.Resource {
+	var resources []k8s.Resource
+	seen := map[k8s.Resource]bool{}
+	for _, scanner := range e.scanners {
+		wanted := scanner.Resources()
+		if optional, ok := scanner.(OptionalResourceScanner); ok {
+			wanted = append(wanted[:len(wanted):len(wanted)], optional.OptionalResources()...)
+		}
+		for _, r := range wanted {
+			if !seen[r] && !e.excluded[r] {
+				seen[r] = true
+				resources = append(resources, r)
+			}
+		}
 	}
+	return resources
 }
 
-// Run executes all registered scanners
+// missingResource describes why a resource a scanner reads is not in the
+// snapshot, or returns nil when it was collected
+func (e *Engine) missingResource(snap *k8s.Snapshot, scanner Scanner, r k8s.Resource) *ScanError {
+	if snap.Has(r) {
+		return nil
+	}
+	scanErr := &ScanError{
+		Scanner:    scanner.Name(),
+		Reason:     k8s.ReasonError,
+		Resource:   r.String(),
+		Namespaces: affectedNamespaces(snap.Namespace),
+		Message:    fmt.Sprintf("resource %s was not collected", r),
+	}
+	if e.excluded[r] {
+		scanErr.Reason = k8s.ReasonExcluded
+		scanErr.Message = fmt.Sprintf("resource %s was excluded from the scan", r)
+	} else if collectErr := snap.ErrorFor(r); collectErr != nil {
+		scanErr.Reason = collectErr.Reason
+		scanErr.Namespaces = affectedNamespaces(collectErr.Namespace)
+		scanErr.Message = collectErr.Message
+	}
+	return scanErr
+}
+
+// Run lists every resource the scanners need once and evaluates the resulting snapshot
 func (e *Engine) Run(ctx context.Context, namespace string) (*Result, error) {
+	snap, err := k8s.Collect(ctx, e.client, namespace, e.Resources(), e.workers)
+	if err != nil {
+		logger.Error("Snapshot is incomplete", "error", err)
+	}
+	return e.Evaluate(ctx, snap)
+}
+
+// Evaluate executes all registered scanners against a snapshot concurrently,
+// bounded by the configured worker count
+func (e *Engine) Evaluate(ctx context.Context, snap *k8s.Snapshot) (*Result, error) {
 	result := &Result{
-		Issues: []Issue{},
+		Cluster: snap.Cluster,
+		Node:    e.node,
+		Issues:  []Issue{},
+		Errors:  []ScanError{},
 		Stats: Stats{
 			SeverityCount: map[Severity]int{
 				SeverityCritical: 0,
@@ -37,55 +195,198 @@ func (e *Engine) Run(ctx context.Context, namespace string) (*Result, error) {
 				SeverityLow:      0,
 				SeverityInfo:     0,
 			},
-			TotalIssues:      0,
-			ResourcesScanned: 0,
+			TotalIssues:          0,
+			ResourcesScanned:     0,
+			ResourcesByKind:      map[string]int{},
+			ResourcesByNamespace: map[string]int{},
+			Rules:                []RuleStats{},
+			Scanners:             []ScannerStats{},
 		},
 	}
 
-	for _, scanner := range e.scanners {
-		issues, err := scanner.Scan(ctx, e.client, namespace)
-		if err != nil {
-			logger.Error("Scanner failed partially", "error", err, "scanner", fmt.Sprintf("%T", scanner))
-			// We continue to allow other scanners to run
+	// Each scanner writes into its own slot so results keep registration order
+	outcomes := make([]scanOutcome, len(e.scanners))
+	sem := make(chan struct{}, e.workers)
+	var wg sync.WaitGroup
+
+	for i, scanner := range e.scanners {
+		wg.Add(1)
+		go func(i int, scanner Scanner) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Skip scanners whose input could not be listed rather than
+			// reporting a misleadingly clean result for them
+			var missing []ScanError
+			for _, r := range scanner.Resources() {
+				if scanErr := e.missingResource(snap, scanner, r); scanErr != nil {
+					missing = append(missing, *scanErr)
+				}
+			}
+			if len(missing) > 0 {
+				outcomes[i] = scanOutcome{rec: NewRecorder(), errs: missing}
+				return
+			}
+
+			// Missing optional input only skips the checks that need it, and
+			// partly collected input leaves the results unverified
+			var gaps []ScanError
+			for _, r := range scanner.Resources() {
+				if collectErr := snap.ErrorFor(r); collectErr != nil {
+					gaps = append(gaps, ScanError{
+						Scanner:    scanner.Name(),
+						Reason:     collectErr.Reason,
+						Resource:   r.String(),
+						Namespaces: affectedNamespaces(collectErr.Namespace),
+						Message:    collectErr.Message,
+					})
+				}
+			}
+			if optional, ok := scanner.(OptionalResourceScanner); ok {
+				for _, r := range optional.OptionalResources() {
+					if scanErr := e.missingResource(snap, scanner, r); scanErr != nil {
+						scanErr.Message += "; checks that need it were skipped"
+						gaps = append(gaps, *scanErr)
+					}
+				}
+			}
+
+			rec := NewRecorder()
+			start := time.Now()
+			err := scanner.Scan(ctx, snap, rec)
+			outcomes[i] = scanOutcome{rec: rec, gaps: gaps, duration: time.Since(start)}
+			if err != nil {
+				outcomes[i].errs = []ScanError{{
+					Scanner:    scanner.Name(),
+					Reason:     k8s.ErrorReason(err),
+					Namespaces: affectedNamespaces(snap.Namespace),
+					Message:    err.Error(),
+				}}
+			}
+		}(i, scanner)
+	}
+	wg.Wait()
+
+	objects := map[objectKey]bool{}
+	rules := map[string]*RuleStats{}
+
+	for i, scanner := range e.scanners {
+		outcome := outcomes[i]
+		result.Stats.Scanners = append(result.Stats.Scanners, ScannerStats{
+			Name:     scanner.Name(),
+			Duration: outcome.duration,
+			Issues:   len(outcome.rec.issues),
+			Objects:  len(outcome.rec.objects),
+			Checks:   outcome.rec.checks(),
+		})
+
+		if len(outcome.errs) > 0 {
+			for _, scanErr := range outcome.errs {
+				logger.Error("Scanner failed partially", "error", scanErr.Message, "reason", scanErr.Reason, "scanner", scanner.Name())
+			}
+			// Record the gap in the result and continue to allow other scanners to run
+			for _, scanErr := range outcome.errs {
+				scanErr.Cluster = snap.Cluster
+				result.Errors = append(result.Errors, scanErr)
+			}
 			continue
 		}
+		for _, scanErr := range outcome.gaps {
+			logger.Warn("Scanner skipped checks", "error", scanErr.Message, "reason", scanErr.Reason, "scanner", scanner.Name())
+			scanErr.Cluster = snap.Cluster
+			result.Errors = append(result.Errors, scanErr)
+		}
 
-		for _, issue := range issues {
+		for _, issue := range outcome.rec.issues {
+			issue.Cluster = snap.Cluster
 			result.Issues = append(result.Issues, issue)
 			result.Stats.TotalIssues++
 			result.Stats.SeverityCount[issue.Severity]++
 		}
+
+		for key := range outcome.rec.objects {
+			objects[key] = true
+		}
+		for id, stats := range outcome.rec.rules {
+			merged, ok := rules[id]
+			if !ok {
+				merged = &RuleStats{ID: id}
+				rules[id] = merged
+			}
+			merged.Passed += stats.Passed
+			merged.Failed += stats.Failed
+		}
+	}
+
+	// Objects inspected by several scanners are only counted once
+	for key := range objects {
+		result.Stats.ResourcesScanned++
+		result.Stats.ResourcesByKind[key.kind]++
+		if key.namespace != "" {
+			result.Stats.ResourcesByNamespace[key.namespace]++
+		}
+	}
+
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		stats := *rules[id]
+		stats.Evaluated = stats.Passed + stats.Failed
+		result.Stats.Rules = append(result.Stats.Rules, stats)
+		result.Stats.ChecksPassed += stats.Passed
+		result.Stats.ChecksFailed += stats.Failed
 	}
 
 	return result, nil
 }
 
+// isPrivileged reports whether a container runs with 'privileged: true'
+func isPrivileged(container corev1.Container) bool {
+	return container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged
+}
+
 // PodScanner audits Pod configurations
 type PodScanner struct{}
 
-// Scan audits pods in the given namespace
-func (p *PodScanner) Scan(ctx context.Context, client *k8s.Client, namespace string) ([]Issue, error) {
-	var issues []Issue
+// Name returns the scanner identifier
+func (p *PodScanner) Name() string {
+	return "pods"
+}
 
-	pods, err := client.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
-	}
+// Resources returns the resources the pod scanner reads
+func (p *PodScanner) Resources() []k8s.Resource {
+	return []k8s.Resource{k8s.ResourcePods}
+}
+
+// Scan audits the pods captured in the snapshot
+func (p *PodScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	for _, pod := range snap.Pods {
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		p.checkHostAccess(rec, &pod)
 
-	for _, pod := range pods.Items {
 		// Example check: Privileged container
-		for _, container := range pod.Spec.Containers {
-			if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
-				issues = append(issues, Issue{
-					ID:          "HK-001",
-					Title:       "Privileged Container Detected",
-					Description: fmt.Sprintf("Pod %s in namespace %s has a privileged container: %s", pod.Name, pod.Namespace, container.Name),
-					Severity:    SeverityCritical,
-					Resource:    pod.Name,
-					Namespace:   pod.Namespace,
-					Remediation: "Remove 'privileged: true' from securityContext.",
-					Category:    "Pod Security",
+		for _, c := range podContainers(&pod.Spec) {
+			container := c.Container
+			if isPrivileged(container) {
+				rec.Fail(Issue{
+					ID:            "HK-001",
+					Title:         "Privileged Container Detected",
+					Description:   fmt.Sprintf("Pod %s in namespace %s has a privileged container: %s", pod.Name, pod.Namespace, container.Name),
+					Severity:      SeverityCritical,
+					Resource:      pod.Name,
+					Namespace:     pod.Namespace,
+					Remediation:   "Remove 'privileged: true' from securityContext.",
+					Category:      CategoryPodSecurity,
+					Container:     container.Name,
+					ContainerType: c.Type,
 				})
+			} else {
+				rec.Pass("HK-001")
 			}
 
 			// Check: ReadOnlyRootFilesystem
@@ -96,16 +397,20 @@ func (p *PodScanner) Scan(ctx context.Context, client *k8s.Client, namespace str
 			// Note: readOnlyRootFilesystem is only in Container.SecurityContext, not Pod.SecurityContext
 
 			if !isReadOnly {
-				issues = append(issues, Issue{
-					ID:          "HK-002",
-					Title:       "Writable Root Filesystem",
-					Description: fmt.Sprintf("Pod %s in namespace %s has a container with a writable root filesystem: %s", pod.Name, pod.Namespace, container.Name),
-					Severity:    SeverityMedium,
-					Resource:    pod.Name,
-					Namespace:   pod.Namespace,
-					Remediation: "Set 'readOnlyRootFilesystem: true' in securityContext.",
-					Category:    "Pod Security",
+				rec.Fail(Issue{
+					ID:            "HK-002",
+					Title:         "Writable Root Filesystem",
+					Description:   fmt.Sprintf("Pod %s in namespace %s has a container with a writable root filesystem: %s", pod.Name, pod.Namespace, container.Name),
+					Severity:      SeverityMedium,
+					Resource:      pod.Name,
+					Namespace:     pod.Namespace,
+					Remediation:   "Set 'readOnlyRootFilesystem: true' in securityContext.",
+					Category:      CategoryPodSecurity,
+					Container:     container.Name,
+					ContainerType: c.Type,
 				})
+			} else {
+				rec.Pass("HK-002")
 			}
 
 			// Check: RunAsNonRoot
@@ -117,19 +422,25 @@ func (p *PodScanner) Scan(ctx context.Context, client *k8s.Client, namespace str
 			}
 
 			if !runAsNonRoot {
-				issues = append(issues, Issue{
-					ID:          "HK-003",
-					Title:       "Run As Root Allowed",
-					Description: fmt.Sprintf("Pod %s in namespace %s does not enforce 'runAsNonRoot': %s", pod.Name, pod.Namespace, container.Name),
-					Severity:    SeverityHigh,
-					Resource:    pod.Name,
-					Namespace:   pod.Namespace,
-					Remediation: "Set 'runAsNonRoot: true' in securityContext.",
-					Category:    "Pod Security",
+				rec.Fail(Issue{
+					ID:            "HK-003",
+					Title:         "Run As Root Allowed",
+					Description:   fmt.Sprintf("Pod %s in namespace %s does not enforce 'runAsNonRoot': %s", pod.Name, pod.Namespace, container.Name),
+					Severity:      SeverityHigh,
+					Resource:      pod.Name,
+					Namespace:     pod.Namespace,
+					Remediation:   "Set 'runAsNonRoot: true' in securityContext.",
+					Category:      CategoryPodSecurity,
+					Container:     container.Name,
+					ContainerType: c.Type,
 				})
+			} else {
+				rec.Pass("HK-003")
 			}
+
+			p.checkContainerHardening(rec, &pod, c)
 		}
 	}
 
-	return issues, nil
+	return nil
 }
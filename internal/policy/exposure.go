@@ -0,0 +1,314 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// exposureLevel ranks how reachable a service is from outside the cluster
+type exposureLevel int
+
+const (
+	exposureInternal exposureLevel = iota
+	// exposureRestricted covers NodePorts, internal load balancers and load
+	// balancers limited to source ranges
+	exposureRestricted
+	exposurePublic
+)
+
+// internalLoadBalancerAnnotations mark cloud load balancers that only get a
+// private address. A value of "false" turns the behaviour off.
+var internalLoadBalancerAnnotations = []string{
+	"service.beta.kubernetes.io/aws-load-balancer-internal",
+	"service.beta.kubernetes.io/azure-load-balancer-internal",
+	"networking.gke.io/load-balancer-type",
+	"cloud.google.com/load-balancer-type",
+}
+
+// sourceRangesAnnotation is the legacy alternative to spec.loadBalancerSourceRanges
+const sourceRangesAnnotation = "service.beta.kubernetes.io/load-balancer-source-ranges"
+
+// internalLoadBalancer reports whether a LoadBalancer service only gets a private address
+func internalLoadBalancer(svc *corev1.Service) bool {
+	for _, key := range internalLoadBalancerAnnotations {
+		if value, ok := svc.Annotations[key]; ok && !strings.EqualFold(value, "false") && !strings.EqualFold(value, "external") {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictedSourceRanges reports whether a LoadBalancer only accepts clients
+// from specific networks
+func restrictedSourceRanges(svc *corev1.Service) bool {
+	ranges := svc.Spec.LoadBalancerSourceRanges
+	if len(ranges) == 0 && svc.Annotations[sourceRangesAnnotation] != "" {
+		ranges = strings.Split(svc.Annotations[sourceRangesAnnotation], ",")
+	}
+	if len(ranges) == 0 {
+		return false
+	}
+	for _, r := range ranges {
+		if r = strings.TrimSpace(r); r == "0.0.0.0/0" || r == "::/0" {
+			return false
+		}
+	}
+	return true
+}
+
+// serviceExposure rates a service and explains how it is reachable
+func serviceExposure(snap *k8s.Snapshot, svc *corev1.Service) (exposureLevel, string) {
+	if len(svc.Spec.ExternalIPs) > 0 {
+		return exposurePublic, "externalIPs"
+	}
+	if ingresses := snap.IngressesForService(svc.Namespace, svc.Name); len(ingresses) > 0 {
+		return exposurePublic, "Ingress " + ingresses[0].Name
+	}
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		switch {
+		case internalLoadBalancer(svc):
+			return exposureRestricted, "internal LoadBalancer"
+		case restrictedSourceRanges(svc):
+			return exposureRestricted, "LoadBalancer limited to source ranges"
+		default:
+			return exposurePublic, "public LoadBalancer"
+		}
+	case corev1.ServiceTypeNodePort:
+		return exposureRestricted, "NodePort"
+	}
+	return exposureInternal, "ClusterIP"
+}
+
+// podExposure returns the highest exposure of the services selecting a pod
+// and describes the routes that reach it from outside the cluster. Ingress
+// routes are only seen when the snapshot holds Ingresses.
+func podExposure(snap *k8s.Snapshot, pod *corev1.Pod) (exposureLevel, []string) {
+	level := exposureInternal
+	var via []string
+	for _, svc := range snap.ServicesSelecting(pod) {
+		svcLevel, how := serviceExposure(snap, svc)
+		if svcLevel == exposureInternal {
+			continue
+		}
+		via = append(via, fmt.Sprintf("service %s (%s)", svc.Name, how))
+		if svcLevel > level {
+			level = svcLevel
+		}
+	}
+	return level, via
+}
+
+// privilegedReasons lists the settings that give a pod control over its node
+func privilegedReasons(pod *corev1.Pod) []string {
+	var reasons []string
+	if pod.Spec.HostNetwork {
+		reasons = append(reasons, "hostNetwork")
+	}
+	if pod.Spec.HostPID {
+		reasons = append(reasons, "hostPID")
+	}
+	if pod.Spec.HostIPC {
+		reasons = append(reasons, "hostIPC")
+	}
+	for _, container := range podContainers(&pod.Spec) {
+		if isPrivileged(container.Container) {
+			reasons = append(reasons, "privileged container "+container.Name)
+			continue
+		}
+		if sc := container.SecurityContext; sc != nil && sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if name := normalizeCapability(capability); dangerousCapabilities[name] == SeverityCritical {
+					reasons = append(reasons, fmt.Sprintf("%s in container %s", name, container.Name))
+				}
+			}
+		}
+	}
+	return reasons
+}
+
+// ExposureScanner audits how services and Ingresses expose workloads
+type ExposureScanner struct{}
+
+// Name returns the scanner identifier
+func (s *ExposureScanner) Name() string {
+	return "exposure"
+}
+
+// Resources returns the resources the exposure scanner reads
+func (s *ExposureScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{
+		k8s.ResourcePods,
+		k8s.ResourceServices,
+		k8s.ResourceIngresses,
+	}, k8s.WorkloadResources...)
+}
+
+// Scan runs the exposure checks
+func (s *ExposureScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	for i := range snap.Services {
+		s.checkService(rec, snap, &snap.Services[i])
+	}
+	for i := range snap.Ingresses {
+		s.checkIngress(rec, &snap.Ingresses[i])
+	}
+	return nil
+}
+
+func (s *ExposureScanner) checkService(rec *Recorder, snap *k8s.Snapshot, svc *corev1.Service) {
+	rec.Scanned("Service", svc.Namespace, svc.Name)
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("Service %s in namespace %s %s", svc.Name, svc.Namespace, description),
+			Severity:    severity,
+			Resource:    "Service/" + svc.Name,
+			Namespace:   svc.Namespace,
+			Remediation: remediation,
+			Category:    CategoryExposure,
+		}
+	}
+
+	// Check: public load balancers
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		if !internalLoadBalancer(svc) && !restrictedSourceRanges(svc) {
+			rec.Fail(issue("HK-034", "Unrestricted LoadBalancer Service",
+				"is a public LoadBalancer without loadBalancerSourceRanges",
+				SeverityMedium, "Set 'loadBalancerSourceRanges' to the client networks, or use an internal load balancer."))
+		} else {
+			rec.Pass("HK-034")
+		}
+	}
+
+	// Check: NodePort opens the port on every node
+	if svc.Spec.Type == corev1.ServiceTypeNodePort {
+		rec.Fail(issue("HK-035", "NodePort Service",
+			"is a NodePort service, which opens its port on every node",
+			SeverityLow, "Use a ClusterIP service behind an Ingress or a LoadBalancer restricted to known sources."))
+	} else {
+		rec.Pass("HK-035")
+	}
+
+	// Check: externalIPs can intercept traffic for any address (CVE-2020-8554)
+	if len(svc.Spec.ExternalIPs) > 0 {
+		rec.Fail(issue("HK-036", "Service With externalIPs",
+			fmt.Sprintf("sets externalIPs %s, which can be used to intercept cluster traffic (CVE-2020-8554)", strings.Join(svc.Spec.ExternalIPs, ", ")),
+			SeverityHigh, "Remove 'externalIPs' and block the field with an admission policy such as the DenyServiceExternalIPs plugin."))
+	} else {
+		rec.Pass("HK-036")
+	}
+
+	// Check: services selecting privileged pods, ranked by how exposed the service is
+	if len(svc.Spec.Selector) == 0 {
+		return
+	}
+	workloads := map[string]bool{}
+	for _, pod := range snap.PodsMatchingLabels(svc.Namespace, svc.Spec.Selector) {
+		if len(privilegedReasons(pod)) > 0 {
+			workload := snap.WorkloadFor(pod)
+			workloads[workload.Kind+"/"+workload.Name] = true
+		}
+	}
+	if len(workloads) == 0 {
+		rec.Pass("HK-039")
+		return
+	}
+
+	names := make([]string, 0, len(workloads))
+	for name := range workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	level, how := serviceExposure(snap, svc)
+	severity := map[exposureLevel]Severity{
+		exposureInternal:   SeverityMedium,
+		exposureRestricted: SeverityHigh,
+		exposurePublic:     SeverityCritical,
+	}[level]
+	rec.Fail(issue("HK-039", "Service Selects Privileged Pods",
+		fmt.Sprintf("(%s) routes traffic to privileged workloads: %s", how, strings.Join(names, ", ")),
+		severity, "Move the privileged component out of the serving path or drop its host and privileged settings."))
+}
+
+func (s *ExposureScanner) checkIngress(rec *Recorder, ing *networkingv1.Ingress) {
+	rec.Scanned("Ingress", ing.Namespace, ing.Name)
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("Ingress %s in namespace %s %s", ing.Name, ing.Namespace, description),
+			Severity:    severity,
+			Resource:    "Ingress/" + ing.Name,
+			Namespace:   ing.Namespace,
+			Remediation: remediation,
+			Category:    CategoryExposure,
+		}
+	}
+
+	tlsHosts := map[string]bool{}
+	for _, tls := range ing.Spec.TLS {
+		for _, host := range tls.Hosts {
+			tlsHosts[host] = true
+		}
+	}
+
+	var plaintext, wildcard []string
+	catchAll := ing.Spec.DefaultBackend != nil
+	for _, rule := range ing.Spec.Rules {
+		switch {
+		case rule.Host == "":
+			catchAll = true
+		case strings.HasPrefix(rule.Host, "*"):
+			wildcard = append(wildcard, rule.Host)
+		}
+		if rule.Host != "" && !tlsCovers(tlsHosts, rule.Host) {
+			plaintext = append(plaintext, rule.Host)
+		}
+	}
+
+	// Check: hosts served without TLS
+	switch {
+	case len(ing.Spec.TLS) == 0:
+		rec.Fail(issue("HK-037", "Ingress Without TLS",
+			"has no TLS configuration, so traffic is served in plain HTTP",
+			SeverityMedium, "Add a 'tls' section with a certificate for every host."))
+	case len(plaintext) > 0:
+		rec.Fail(issue("HK-037", "Ingress Without TLS",
+			fmt.Sprintf("serves hosts without TLS: %s", strings.Join(plaintext, ", ")),
+			SeverityMedium, "Add a 'tls' section with a certificate for every host."))
+	default:
+		rec.Pass("HK-037")
+	}
+
+	// Check: wildcard and catch-all hosts accept traffic for names nobody reviewed
+	switch {
+	case catchAll:
+		rec.Fail(issue("HK-038", "Wildcard Ingress Host",
+			"accepts requests for any host name",
+			SeverityMedium, "Set an explicit 'host' on every rule and avoid a default backend."))
+	case len(wildcard) > 0:
+		rec.Fail(issue("HK-038", "Wildcard Ingress Host",
+			fmt.Sprintf("uses wildcard hosts: %s", strings.Join(wildcard, ", ")),
+			SeverityLow, "Replace wildcard hosts with the exact host names that are served."))
+	default:
+		rec.Pass("HK-038")
+	}
+}
+
+// tlsCovers reports whether a host is listed in an Ingress TLS section,
+// either exactly or through a wildcard that matches its first label only
+func tlsCovers(tlsHosts map[string]bool, host string) bool {
+	if tlsHosts[host] {
+		return true
+	}
+	_, parent, ok := strings.Cut(host, ".")
+	return ok && !strings.HasPrefix(host, "*") && tlsHosts["*."+parent]
+}
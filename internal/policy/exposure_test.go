@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExposureScannerServices(t *testing.T) {
+	privileged := true
+	pod := func(name string, privileged *bool) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": name}},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app", SecurityContext: &corev1.SecurityContext{Privileged: privileged},
+			}}},
+		}
+	}
+	service := func(name string, svcType corev1.ServiceType, app string) corev1.Service {
+		return corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			Spec:       corev1.ServiceSpec{Type: svcType, Selector: map[string]string{"app": app}},
+		}
+	}
+
+	public := service("public", corev1.ServiceTypeLoadBalancer, "agent")
+	restricted := service("restricted", corev1.ServiceTypeLoadBalancer, "agent")
+	restricted.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	internal := service("internal", corev1.ServiceTypeClusterIP, "agent")
+	hijack := service("hijack", corev1.ServiceTypeClusterIP, "web")
+	hijack.Spec.ExternalIPs = []string{"8.8.8.8"}
+
+	snap := &k8s.Snapshot{
+		Pods:     []corev1.Pod{pod("agent", &privileged), pod("web", nil)},
+		Services: []corev1.Service{public, restricted, internal, hijack, service("nodeport", corev1.ServiceTypeNodePort, "web")},
+	}
+
+	rec := NewRecorder()
+	if err := (&ExposureScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-034": 1, "HK-035": 1, "HK-036": 1, "HK-039": 3})
+
+	// The same privileged pod ranks by how exposed each service is
+	severities := map[string]Severity{}
+	for _, issue := range rec.Issues() {
+		if issue.ID == "HK-039" {
+			severities[issue.Resource] = issue.Severity
+		}
+	}
+	expected := map[string]Severity{
+		"Service/public":     SeverityCritical,
+		"Service/restricted": SeverityHigh,
+		"Service/internal":   SeverityMedium,
+	}
+	for resource, severity := range expected {
+		if severities[resource] != severity {
+			t.Errorf("%s: expected %s, got %s", resource, severity, severities[resource])
+		}
+	}
+}
+
+func TestExposureScannerIngresses(t *testing.T) {
+	backend := networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web"}}
+	rule := func(host string) networkingv1.IngressRule {
+		return networkingv1.IngressRule{Host: host, IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{Path: "/", Backend: backend}}},
+		}}
+	}
+
+	snap := &k8s.Snapshot{Ingresses: []networkingv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "secure", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}}},
+				Rules: []networkingv1.IngressRule{rule("shop.example.com")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "partial", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}}},
+				Rules: []networkingv1.IngressRule{rule("shop.example.com"), rule("*.example.com")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "open", Namespace: "shop"},
+			Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{rule("")}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}}},
+				Rules: []networkingv1.IngressRule{rule("api.example.com"), rule("www.example.com")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wildcard-partial", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}}},
+				Rules: []networkingv1.IngressRule{rule("api.example.com"), rule("a.b.example.com"), rule("example.com")},
+			},
+		},
+	}}
+
+	rec := NewRecorder()
+	if err := (&ExposureScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]Severity{}
+	for _, issue := range rec.Issues() {
+		got[issue.Resource+"/"+issue.ID] = issue.Severity
+		// A wildcard certificate covers exactly one extra label
+		if issue.Resource == "Ingress/wildcard-partial" && !strings.HasSuffix(issue.Description, "a.b.example.com, example.com") {
+			t.Errorf("unexpected hosts without TLS: %s", issue.Description)
+		}
+	}
+	want := map[string]Severity{
+		"Ingress/wildcard-partial/HK-037": SeverityMedium,
+		"Ingress/partial/HK-037":          SeverityMedium,
+		"Ingress/partial/HK-038":          SeverityLow,
+		"Ingress/open/HK-037":             SeverityMedium,
+		"Ingress/open/HK-038":             SeverityMedium,
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d issues, got %v", len(want), got)
+	}
+	for key, severity := range want {
+		if got[key] != severity {
+			t.Errorf("%s: expected %s, got %q", key, severity, got[key])
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"sort"
+)
+
+// severityWeight ranks clusters by how much risk their open issues carry
+var severityWeight = map[Severity]int{
+	SeverityCritical: 10,
+	SeverityHigh:     5,
+	SeverityMedium:   2,
+	SeverityLow:      1,
+}
+
+// FleetSummary compares the clusters that make up a merged result
+type FleetSummary struct {
+	// Clusters is ordered worst first
+	Clusters []ClusterSummary `json:"clusters" yaml:"clusters"`
+	// CommonFindings lists rules that fail on more than one cluster
+	CommonFindings []CommonFinding `json:"common_findings" yaml:"common_findings"`
+}
+
+// ClusterSummary holds the headline numbers for one cluster
+type ClusterSummary struct {
+	Cluster string `json:"cluster" yaml:"cluster"`
+	// Node is set while the summary covers only node audits of the cluster
+	Node          string           `json:"node,omitempty" yaml:"node,omitempty"`
+	TotalIssues   int              `json:"total_issues" yaml:"total_issues"`
+	SeverityCount map[Severity]int `json:"severity_count" yaml:"severity_count"`
+	ChecksPassed  int              `json:"checks_passed" yaml:"checks_passed"`
+	ChecksFailed  int              `json:"checks_failed" yaml:"checks_failed"`
+	// Score is the percentage of checks that passed
+	Score int `json:"score" yaml:"score"`
+	// Risk weights open issues by severity; higher is worse
+	Risk    int  `json:"risk" yaml:"risk"`
+	Partial bool `json:"partial" yaml:"partial"`
+}
+
+// CommonFinding is a rule failing on several clusters
+type CommonFinding struct {
+	ID          string   `json:"id" yaml:"id"`
+	Title       string   `json:"title" yaml:"title"`
+	Severity    Severity `json:"severity" yaml:"severity"`
+	Clusters    []string `json:"clusters" yaml:"clusters"`
+	Occurrences int      `json:"occurrences" yaml:"occurrences"`
+}
+
+// Summarize computes the per-cluster summary of a single-cluster result
+func Summarize(result *Result) ClusterSummary {
+	summary := ClusterSummary{
+		Cluster:       result.Cluster,
+		Node:          result.Node,
+		TotalIssues:   result.Stats.TotalIssues,
+		SeverityCount: map[Severity]int{},
+		ChecksPassed:  result.Stats.ChecksPassed,
+		ChecksFailed:  result.Stats.ChecksFailed,
+		Partial:       result.Partial(),
+	}
+	for sev, count := range result.Stats.SeverityCount {
+		summary.SeverityCount[sev] = count
+		summary.Risk += severityWeight[sev] * count
+	}
+	if checks := summary.ChecksPassed + summary.ChecksFailed; checks > 0 {
+		summary.Score = summary.ChecksPassed * 100 / checks
+	}
+	return summary
+}
+
+// addSummary appends a cluster summary. Node audits are folded into the
+// entry for their cluster, so a cluster scan and the audits of its nodes
+// are ranked as one; separate scans of a cluster are kept side by side.
+func addSummary(clusters []ClusterSummary, summary ClusterSummary) []ClusterSummary {
+	for i := range clusters {
+		existing := &clusters[i]
+		if summary.Cluster == "" || existing.Cluster != summary.Cluster || (summary.Node == "" && existing.Node == "") {
+			continue
+		}
+		if summary.Node == "" {
+			// The cluster scan now stands for the entry
+			existing.Node = ""
+		}
+		existing.TotalIssues += summary.TotalIssues
+		existing.ChecksPassed += summary.ChecksPassed
+		existing.ChecksFailed += summary.ChecksFailed
+		existing.Risk += summary.Risk
+		existing.Partial = existing.Partial || summary.Partial
+
+		// The map may be shared with the result the summary came from
+		severityCount := make(map[Severity]int, len(existing.SeverityCount))
+		for sev, count := range existing.SeverityCount {
+			severityCount[sev] = count
+		}
+		for sev, count := range summary.SeverityCount {
+			severityCount[sev] += count
+		}
+		existing.SeverityCount = severityCount
+
+		existing.Score = 0
+		if checks := existing.ChecksPassed + existing.ChecksFailed; checks > 0 {
+			existing.Score = existing.ChecksPassed * 100 / checks
+		}
+		return clusters
+	}
+	return append(clusters, summary)
+}
+
+// Merge combines per-cluster results into one fleet-wide result. Every issue
+// and error keeps the cluster it came from, and the Fleet summary compares
+// the clusters side by side. Results that were already merged are flattened.
+func Merge(results ...*Result) *Result {
+	merged := &Result{
+		Issues: []Issue{},
+		Errors: []ScanError{},
+		Stats: Stats{
+			SeverityCount:        map[Severity]int{},
+			ResourcesByKind:      map[string]int{},
+			ResourcesByNamespace: map[string]int{},
+			Rules:                []RuleStats{},
+			Scanners:             []ScannerStats{},
+		},
+		Fleet: &FleetSummary{
+			Clusters:       []ClusterSummary{},
+			CommonFindings: []CommonFinding{},
+		},
+	}
+
+	rules := map[string]*RuleStats{}
+	common := map[string]*CommonFinding{}
+	commonClusters := map[string]map[string]bool{}
+
+	for _, result := range results {
+		if result.Fleet != nil {
+			for _, summary := range result.Fleet.Clusters {
+				merged.Fleet.Clusters = addSummary(merged.Fleet.Clusters, summary)
+			}
+		} else {
+			merged.Fleet.Clusters = addSummary(merged.Fleet.Clusters, Summarize(result))
+		}
+
+		for _, issue := range result.Issues {
+			if issue.Cluster == "" {
+				issue.Cluster = result.Cluster
+			}
+			merged.Issues = append(merged.Issues, issue)
+
+			finding, ok := common[issue.ID]
+			if !ok {
+				finding = &CommonFinding{ID: issue.ID, Title: issue.Title, Severity: issue.Severity}
+				common[issue.ID] = finding
+				commonClusters[issue.ID] = map[string]bool{}
+			}
+			finding.Occurrences++
+			commonClusters[issue.ID][issue.Cluster] = true
+		}
+
+		for _, scanErr := range result.Errors {
+			if scanErr.Cluster == "" {
+				scanErr.Cluster = result.Cluster
+			}
+			merged.Errors = append(merged.Errors, scanErr)
+		}
+
+		stats := result.Stats
+		merged.Stats.TotalIssues += stats.TotalIssues
+		merged.Stats.ResourcesScanned += stats.ResourcesScanned
+		merged.Stats.ChecksPassed += stats.ChecksPassed
+		merged.Stats.ChecksFailed += stats.ChecksFailed
+		for sev, count := range stats.SeverityCount {
+			merged.Stats.SeverityCount[sev] += count
+		}
+		for kind, count := range stats.ResourcesByKind {
+			merged.Stats.ResourcesByKind[kind] += count
+		}
+		// Namespace names repeat across clusters, so qualify them
+		for ns, count := range stats.ResourcesByNamespace {
+			key := ns
+			if result.Cluster != "" {
+				key = result.Cluster + "/" + ns
+			}
+			merged.Stats.ResourcesByNamespace[key] += count
+		}
+		for _, rule := range stats.Rules {
+			total, ok := rules[rule.ID]
+			if !ok {
+				total = &RuleStats{ID: rule.ID}
+				rules[rule.ID] = total
+			}
+			total.Evaluated += rule.Evaluated
+			total.Passed += rule.Passed
+			total.Failed += rule.Failed
+		}
+		merged.Stats.Scanners = append(merged.Stats.Scanners, stats.Scanners...)
+	}
+
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		merged.Stats.Rules = append(merged.Stats.Rules, *rules[id])
+	}
+
+	for id, finding := range common {
+		if len(commonClusters[id]) < 2 {
+			continue
+		}
+		for cluster := range commonClusters[id] {
+			finding.Clusters = append(finding.Clusters, cluster)
+		}
+		sort.Strings(finding.Clusters)
+		merged.Fleet.CommonFindings = append(merged.Fleet.CommonFindings, *finding)
+	}
+	sort.Slice(merged.Fleet.CommonFindings, func(i, j int) bool {
+		a, b := merged.Fleet.CommonFindings[i], merged.Fleet.CommonFindings[j]
+		if len(a.Clusters) != len(b.Clusters) {
+			return len(a.Clusters) > len(b.Clusters)
+		}
+		return a.ID < b.ID
+	})
+
+	sort.SliceStable(merged.Fleet.Clusters, func(i, j int) bool {
+		a, b := merged.Fleet.Clusters[i], merged.Fleet.Clusters[j]
+		if a.Risk != b.Risk {
+			return a.Risk > b.Risk
+		}
+		return a.Score < b.Score
+	})
+
+	return merged
+}
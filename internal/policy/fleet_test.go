@@ -0,0 +1,125 @@
+package policy
+
+import (
+	"testing"
+)
+
+func TestMergeTagsClustersAndRanksWorstFirst(t *testing.T) {
+	prod := &Result{
+		Cluster: "prod",
+		Issues: []Issue{
+			{ID: "HK-001", Title: "Privileged Container Detected", Severity: SeverityCritical},
+			{ID: "HK-002", Title: "Writable Root Filesystem", Severity: SeverityMedium},
+		},
+		Stats: Stats{
+			TotalIssues:          2,
+			SeverityCount:        map[Severity]int{SeverityCritical: 1, SeverityMedium: 1},
+			ResourcesByNamespace: map[string]int{"default": 1},
+			ChecksPassed:         1,
+			ChecksFailed:         2,
+			Rules:                []RuleStats{{ID: "HK-001", Evaluated: 1, Failed: 1}},
+		},
+	}
+	staging := &Result{
+		Cluster: "staging",
+		Issues: []Issue{
+			{ID: "HK-002", Title: "Writable Root Filesystem", Severity: SeverityMedium},
+		},
+		Stats: Stats{
+			TotalIssues:          1,
+			SeverityCount:        map[Severity]int{SeverityMedium: 1},
+			ResourcesByNamespace: map[string]int{"default": 1},
+			ChecksPassed:         3,
+			ChecksFailed:         1,
+			Rules:                []RuleStats{{ID: "HK-001", Evaluated: 1, Passed: 1}},
+		},
+		Errors: []ScanError{{Scanner: "pods", Reason: "Forbidden"}},
+	}
+
+	merged := Merge(staging, prod)
+
+	if merged.Stats.TotalIssues != 3 || merged.Stats.SeverityCount[SeverityMedium] != 2 {
+		t.Errorf("unexpected merged stats: %+v", merged.Stats)
+	}
+	for _, issue := range merged.Issues {
+		if issue.Cluster == "" {
+			t.Errorf("expected every issue to carry its cluster, got %+v", issue)
+		}
+	}
+	if len(merged.Errors) != 1 || merged.Errors[0].Cluster != "staging" {
+		t.Errorf("expected the staging error to be tagged, got %+v", merged.Errors)
+	}
+	if merged.Stats.ResourcesByNamespace["prod/default"] != 1 || merged.Stats.ResourcesByNamespace["staging/default"] != 1 {
+		t.Errorf("expected namespaces to be qualified by cluster, got %v", merged.Stats.ResourcesByNamespace)
+	}
+	if len(merged.Stats.Rules) != 1 || merged.Stats.Rules[0].Evaluated != 2 {
+		t.Errorf("expected rule stats to be summed, got %+v", merged.Stats.Rules)
+	}
+
+	clusters := merged.Fleet.Clusters
+	if len(clusters) != 2 || clusters[0].Cluster != "prod" {
+		t.Fatalf("expected prod to rank worst, got %+v", clusters)
+	}
+	if clusters[1].Score != 75 || !clusters[1].Partial {
+		t.Errorf("expected staging to score 75%% and be partial, got %+v", clusters[1])
+	}
+
+	common := merged.Fleet.CommonFindings
+	if len(common) != 1 || common[0].ID != "HK-002" || len(common[0].Clusters) != 2 {
+		t.Errorf("expected HK-002 to be shared by both clusters, got %+v", common)
+	}
+}
+
+func TestMergeFoldsNodeAuditsIntoTheirCluster(t *testing.T) {
+	scan := &Result{
+		Cluster: "prod",
+		Issues:  []Issue{{ID: "HK-001", Severity: SeverityCritical}},
+		Stats: Stats{
+			TotalIssues:   1,
+			SeverityCount: map[Severity]int{SeverityCritical: 1},
+			ChecksPassed:  3,
+			ChecksFailed:  1,
+		},
+	}
+	node := &Result{
+		Cluster: "prod",
+		Node:    "worker-1",
+		Issues:  []Issue{{ID: "HK-064", Severity: SeverityHigh}},
+		Stats: Stats{
+			TotalIssues:   1,
+			SeverityCount: map[Severity]int{SeverityHigh: 1},
+			ChecksPassed:  5,
+			ChecksFailed:  1,
+		},
+	}
+
+	merged := Merge(scan, node)
+
+	clusters := merged.Fleet.Clusters
+	if len(clusters) != 1 {
+		t.Fatalf("expected one summary for prod, got %+v", clusters)
+	}
+	if clusters[0].TotalIssues != 2 || clusters[0].Score != 80 || clusters[0].Risk != 15 {
+		t.Errorf("expected the node audit to be added to prod, got %+v", clusters[0])
+	}
+	if clusters[0].Node != "" {
+		t.Errorf("expected the summary to stand for the cluster scan, got node %q", clusters[0].Node)
+	}
+
+	// Merging an earlier merge must not change its summaries
+	fleet := Merge(scan)
+	Merge(fleet, node)
+	if count := fleet.Fleet.Clusters[0].SeverityCount; len(count) != 1 || count[SeverityCritical] != 1 {
+		t.Errorf("expected the merged input to be left alone, got %+v", count)
+	}
+}
+
+func TestMergeKeepsSeparateScansOfACluster(t *testing.T) {
+	before := &Result{Cluster: "prod", Stats: Stats{TotalIssues: 4, ChecksPassed: 6, ChecksFailed: 4}}
+	after := &Result{Cluster: "prod", Stats: Stats{TotalIssues: 1, ChecksPassed: 9, ChecksFailed: 1}}
+
+	clusters := Merge(before, after).Fleet.Clusters
+	if len(clusters) != 2 {
+		t.Fatalf("expected both scans of prod to be listed, got %+v", clusters)
+	}
+}
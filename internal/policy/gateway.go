@@ -0,0 +1,244 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"github.com/ismailtsdln/HardenaK8s/internal/logger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The Gateway API types below hold only the fields the scanner reads, so the
+// project does not depend on the Gateway API client library
+
+type gatewayObject struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+type gateway struct {
+	gatewayObject `json:",inline"`
+	Spec          struct {
+		Listeners []gatewayListener `json:"listeners"`
+	} `json:"spec"`
+}
+
+type gatewayListener struct {
+	Name          string `json:"name"`
+	Protocol      string `json:"protocol"`
+	AllowedRoutes *struct {
+		Namespaces *struct {
+			From string `json:"from"`
+		} `json:"namespaces"`
+	} `json:"allowedRoutes"`
+}
+
+type httpRoute struct {
+	gatewayObject `json:",inline"`
+	Spec          struct {
+		ParentRefs []struct {
+			Group     *string `json:"group"`
+			Kind      *string `json:"kind"`
+			Namespace *string `json:"namespace"`
+			Name      string  `json:"name"`
+		} `json:"parentRefs"`
+	} `json:"spec"`
+}
+
+type referenceGrant struct {
+	gatewayObject `json:",inline"`
+	Spec          struct {
+		From []struct {
+			Kind      string `json:"kind"`
+			Namespace string `json:"namespace"`
+		} `json:"from"`
+		To []struct {
+			Group string  `json:"group"`
+			Kind  string  `json:"kind"`
+			Name  *string `json:"name"`
+		} `json:"to"`
+	} `json:"spec"`
+}
+
+// routesFromAll reports whether a listener accepts routes from every namespace
+func (l gatewayListener) routesFromAll() bool {
+	return l.AllowedRoutes != nil && l.AllowedRoutes.Namespaces != nil && l.AllowedRoutes.Namespaces.From == "All"
+}
+
+// GatewayScanner audits Gateway API Gateways, HTTPRoutes and ReferenceGrants.
+// Clusters without the Gateway API CRDs simply have nothing to scan.
+type GatewayScanner struct{}
+
+// Name returns the scanner identifier
+func (s *GatewayScanner) Name() string {
+	return "gateway"
+}
+
+// Resources returns the resources the Gateway API scanner reads
+func (s *GatewayScanner) Resources() []k8s.Resource {
+	return []k8s.Resource{
+		k8s.ResourceGateways,
+		k8s.ResourceHTTPRoutes,
+		k8s.ResourceReferenceGrants,
+	}
+}
+
+// Scan runs the Gateway API checks. An object that cannot be decoded, such
+// as one written against a newer Gateway API version, is logged and skipped
+// so it does not hide the findings on the others.
+func (s *GatewayScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	decode := func(obj unstructured.Unstructured, into interface{}) bool {
+		if err := fromUnstructured(obj, into); err != nil {
+			logger.Warn("Skipping Gateway API object", "error", err)
+			return false
+		}
+		return true
+	}
+
+	gateways := map[string]*gateway{}
+	for _, obj := range snap.Gateways {
+		gw := &gateway{}
+		if !decode(obj, gw) {
+			continue
+		}
+		gateways[gw.Metadata.Namespace+"/"+gw.Metadata.Name] = gw
+		s.checkGateway(rec, gw)
+	}
+
+	for _, obj := range snap.HTTPRoutes {
+		route := &httpRoute{}
+		if !decode(obj, route) {
+			continue
+		}
+		s.checkRoute(rec, gateways, route)
+	}
+
+	for _, obj := range snap.ReferenceGrants {
+		grant := &referenceGrant{}
+		if !decode(obj, grant) {
+			continue
+		}
+		s.checkReferenceGrant(rec, grant)
+	}
+	return nil
+}
+
+// fromUnstructured decodes a Gateway API object into one of the local types
+func fromUnstructured(obj unstructured.Unstructured, into interface{}) error {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into); err != nil {
+		return fmt.Errorf("failed to decode %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// issue builds a Gateway API finding for an object
+func (o gatewayObject) issue(kind, id, title, description string, severity Severity, remediation string) Issue {
+	return Issue{
+		ID:          id,
+		Title:       title,
+		Description: fmt.Sprintf("%s %s in namespace %s %s", kind, o.Metadata.Name, o.Metadata.Namespace, description),
+		Severity:    severity,
+		Resource:    kind + "/" + o.Metadata.Name,
+		Namespace:   o.Metadata.Namespace,
+		Remediation: remediation,
+		Category:    CategoryGatewayAPI,
+	}
+}
+
+func (s *GatewayScanner) checkGateway(rec *Recorder, gw *gateway) {
+	rec.Scanned("Gateway", gw.Metadata.Namespace, gw.Metadata.Name)
+
+	var plaintext, fromAll []string
+	for _, listener := range gw.Spec.Listeners {
+		if listener.Protocol == "HTTP" {
+			plaintext = append(plaintext, listener.Name)
+		}
+		if listener.routesFromAll() {
+			fromAll = append(fromAll, listener.Name)
+		}
+	}
+
+	// Check: listeners serving plain HTTP
+	if len(plaintext) > 0 {
+		rec.Fail(gw.issue("Gateway", "HK-040", "Gateway Listener Without TLS",
+			fmt.Sprintf("has HTTP listeners without TLS: %s", strings.Join(plaintext, ", ")),
+			SeverityMedium, "Serve the listener over HTTPS with a certificate, or keep HTTP only to redirect to HTTPS."))
+	} else {
+		rec.Pass("HK-040")
+	}
+
+	// Check: listeners any namespace can attach routes to
+	if len(fromAll) > 0 {
+		rec.Fail(gw.issue("Gateway", "HK-041", "Gateway Allows Routes From All Namespaces",
+			fmt.Sprintf("lets routes from every namespace attach to listeners: %s", strings.Join(fromAll, ", ")),
+			SeverityMedium, "Set 'allowedRoutes.namespaces.from' to 'Same' or to 'Selector' with a label selector for trusted namespaces."))
+	} else {
+		rec.Pass("HK-041")
+	}
+}
+
+func (s *GatewayScanner) checkRoute(rec *Recorder, gateways map[string]*gateway, route *httpRoute) {
+	rec.Scanned("HTTPRoute", route.Metadata.Namespace, route.Metadata.Name)
+
+	// Check: routes attached to a Gateway in another namespace only because it
+	// accepts routes from everywhere
+	var attached []string
+	for _, ref := range route.Spec.ParentRefs {
+		if ref.Kind != nil && *ref.Kind != "Gateway" {
+			continue
+		}
+		if ref.Group != nil && *ref.Group != "" && *ref.Group != k8s.ResourceGateways.Group {
+			continue
+		}
+		if ref.Namespace == nil || *ref.Namespace == route.Metadata.Namespace {
+			continue
+		}
+		gw, ok := gateways[*ref.Namespace+"/"+ref.Name]
+		if !ok {
+			continue
+		}
+		for _, listener := range gw.Spec.Listeners {
+			if listener.routesFromAll() {
+				attached = append(attached, *ref.Namespace+"/"+ref.Name)
+				break
+			}
+		}
+	}
+	if len(attached) > 0 {
+		rec.Fail(route.issue("HTTPRoute", "HK-043", "Cross-Namespace Route Attachment",
+			fmt.Sprintf("attaches to Gateways in other namespaces that accept routes from all namespaces: %s", strings.Join(attached, ", ")),
+			SeverityMedium, "Restrict the Gateway's 'allowedRoutes' to the namespaces that should publish routes on it."))
+	} else {
+		rec.Pass("HK-043")
+	}
+}
+
+func (s *GatewayScanner) checkReferenceGrant(rec *Recorder, grant *referenceGrant) {
+	rec.Scanned("ReferenceGrant", grant.Metadata.Namespace, grant.Metadata.Name)
+
+	var from []string
+	for _, f := range grant.Spec.From {
+		from = append(from, fmt.Sprintf("%s in %s", f.Kind, f.Namespace))
+	}
+
+	// Check: grants without a name open every object of the kind to the
+	// referencing namespaces; for Secrets that means every certificate key
+	for _, to := range grant.Spec.To {
+		if to.Name != nil && *to.Name != "" {
+			rec.Pass("HK-042")
+			continue
+		}
+		severity := SeverityMedium
+		if to.Group == "" && to.Kind == "Secret" {
+			severity = SeverityHigh
+		}
+		rec.Fail(grant.issue("ReferenceGrant", "HK-042", "Overly Broad ReferenceGrant",
+			fmt.Sprintf("allows %s to reference every %s in the namespace", strings.Join(from, ", "), to.Kind),
+			severity, "Set 'name' on each 'to' entry so only the intended objects can be referenced."))
+	}
+}
@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGatewayScanner(t *testing.T) {
+	object := func(kind, namespace, name string, spec map[string]interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+			"spec":       spec,
+		}}
+	}
+
+	shared := object("Gateway", "infra", "shared", map[string]interface{}{
+		"listeners": []interface{}{
+			map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80),
+				"allowedRoutes": map[string]interface{}{"namespaces": map[string]interface{}{"from": "All"}}},
+			map[string]interface{}{"name": "https", "protocol": "HTTPS", "port": int64(443)},
+		},
+	})
+	private := object("Gateway", "infra", "private", map[string]interface{}{
+		"listeners": []interface{}{
+			map[string]interface{}{"name": "https", "protocol": "HTTPS", "port": int64(443),
+				"allowedRoutes": map[string]interface{}{"namespaces": map[string]interface{}{"from": "Same"}}},
+		},
+	})
+	parent := func(namespace, name string) map[string]interface{} {
+		return map[string]interface{}{"namespace": namespace, "name": name}
+	}
+
+	// Objects that do not decode are skipped without hiding the rest
+	broken := object("Gateway", "infra", "broken", map[string]interface{}{"listeners": "invalid"})
+
+	snap := &k8s.Snapshot{
+		Gateways: []unstructured.Unstructured{broken, shared, private},
+		HTTPRoutes: []unstructured.Unstructured{
+			object("HTTPRoute", "shop", "storefront", map[string]interface{}{"parentRefs": []interface{}{parent("infra", "shared")}}),
+			object("HTTPRoute", "infra", "status", map[string]interface{}{"parentRefs": []interface{}{parent("infra", "shared")}}),
+			object("HTTPRoute", "shop", "broken", map[string]interface{}{"parentRefs": "invalid"}),
+		},
+		ReferenceGrants: []unstructured.Unstructured{
+			object("ReferenceGrant", "certs", "all-secrets", map[string]interface{}{
+				"from": []interface{}{map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "namespace": "infra"}},
+				"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Secret"}},
+			}),
+			object("ReferenceGrant", "shop", "one-service", map[string]interface{}{
+				"from": []interface{}{map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "HTTPRoute", "namespace": "infra"}},
+				"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Service", "name": "web"}},
+			}),
+		},
+	}
+
+	rec := NewRecorder()
+	if err := (&GatewayScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-040": 1, "HK-041": 1, "HK-042": 1, "HK-043": 1})
+
+	for _, issue := range rec.Issues() {
+		switch issue.ID {
+		case "HK-042":
+			if issue.Severity != SeverityHigh {
+				t.Errorf("expected a broad Secret grant to be %s, got %s", SeverityHigh, issue.Severity)
+			}
+		case "HK-043":
+			if issue.Resource != "HTTPRoute/storefront" {
+				t.Errorf("expected only the cross-namespace route to be reported, got %s", issue.Resource)
+			}
+		}
+	}
+}
+
+func TestGatewayScannerWithoutGatewayAPI(t *testing.T) {
+	// Collect records the resources as listed but empty when the CRDs are missing
+	rec := NewRecorder()
+	if err := (&GatewayScanner{}).Scan(context.Background(), &k8s.Snapshot{}, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.Issues()) != 0 {
+		t.Errorf("expected no issues without Gateway API objects, got %v", rec.Issues())
+	}
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// runtimeSockets give full control over every container on the node; a
+// read-only mount does not prevent connecting to them
+var runtimeSockets = []string{
+	"/var/run/docker.sock",
+	"/run/docker.sock",
+	"/run/containerd/containerd.sock",
+	"/var/run/containerd/containerd.sock",
+	"/var/run/crio/crio.sock",
+	"/run/crio/crio.sock",
+}
+
+// sensitiveHostPaths expose node credentials or host control when mounted,
+// including anything below them
+var sensitiveHostPaths = []string{
+	"/",
+	"/etc",
+	"/proc",
+	"/sys",
+	"/root",
+	"/var/lib/kubelet",
+	"/var/lib/docker",
+	"/var/lib/containerd",
+	"/var/run",
+	"/run",
+}
+
+// hostPathSeverity rates a hostPath volume by what it exposes and whether
+// every mount of it is read-only
+func hostPathSeverity(hostPath string, readOnly bool) (Severity, string) {
+	cleaned := path.Clean(hostPath)
+	for _, socket := range runtimeSockets {
+		if cleaned == socket {
+			return SeverityCritical, "exposes the container runtime socket"
+		}
+	}
+	for _, sensitive := range sensitiveHostPaths {
+		if cleaned == sensitive || (sensitive != "/" && strings.HasPrefix(cleaned, sensitive+"/")) {
+			if readOnly {
+				return SeverityHigh, "exposes a sensitive host path read-only"
+			}
+			return SeverityCritical, "exposes a sensitive host path with write access"
+		}
+	}
+	if readOnly {
+		return SeverityLow, "mounts a host path read-only"
+	}
+	return SeverityMedium, "mounts a host path with write access"
+}
+
+// checkHostAccess covers the pod-level settings that share host namespaces,
+// ports and filesystems with the pod
+func (p *PodScanner) checkHostAccess(rec *Recorder, pod *corev1.Pod) {
+	issue := func(id, title, description string, severity Severity, remediation string, container podContainer) Issue {
+		return Issue{
+			ID:            id,
+			Title:         title,
+			Description:   fmt.Sprintf("Pod %s in namespace %s %s", pod.Name, pod.Namespace, description),
+			Severity:      severity,
+			Resource:      pod.Name,
+			Namespace:     pod.Namespace,
+			Remediation:   remediation,
+			Category:      CategoryPodSecurity,
+			Container:     container.Name,
+			ContainerType: container.Type,
+		}
+	}
+
+	namespaces := []struct {
+		id, title, field string
+		enabled          bool
+	}{
+		{"HK-024", "Host Network Namespace Shared", "hostNetwork", pod.Spec.HostNetwork},
+		{"HK-025", "Host PID Namespace Shared", "hostPID", pod.Spec.HostPID},
+		{"HK-026", "Host IPC Namespace Shared", "hostIPC", pod.Spec.HostIPC},
+	}
+	for _, ns := range namespaces {
+		if ns.enabled {
+			rec.Fail(issue(ns.id, ns.title, fmt.Sprintf("sets '%s: true'", ns.field),
+				SeverityHigh, fmt.Sprintf("Remove '%s: true' from the pod spec.", ns.field), podContainer{}))
+		} else {
+			rec.Pass(ns.id)
+		}
+	}
+
+	// Track which containers mount each volume and whether any mount is writable
+	mountedBy := map[string][]podContainer{}
+	writable := map[string]bool{}
+	for _, container := range podContainers(&pod.Spec) {
+		// With hostNetwork every container port is a host port, which
+		// HK-024 already reports
+		if !pod.Spec.HostNetwork {
+			var ports []string
+			for _, port := range container.Ports {
+				if port.HostPort != 0 {
+					ports = append(ports, fmt.Sprintf("%d/%s", port.HostPort, port.Protocol))
+				}
+			}
+			if len(ports) > 0 {
+				rec.Fail(issue("HK-027", "Host Port Bound",
+					fmt.Sprintf("binds host port %s in container %s", strings.Join(ports, ", "), container.Name),
+					SeverityMedium, "Remove 'hostPort' and expose the container through a Service.", container))
+			} else {
+				rec.Pass("HK-027")
+			}
+		}
+
+		for _, mount := range container.VolumeMounts {
+			mountedBy[mount.Name] = append(mountedBy[mount.Name], container)
+			if !mount.ReadOnly {
+				writable[mount.Name] = true
+			}
+		}
+	}
+
+	hostPaths := 0
+	for _, volume := range pod.Spec.Volumes {
+		if volume.HostPath == nil {
+			continue
+		}
+		hostPaths++
+		severity, reason := hostPathSeverity(volume.HostPath.Path, !writable[volume.Name])
+		var container podContainer
+		if len(mountedBy[volume.Name]) == 1 {
+			container = mountedBy[volume.Name][0]
+		}
+		rec.Fail(issue("HK-028", "HostPath Volume",
+			fmt.Sprintf("%s: volume %s mounts %s", reason, volume.Name, volume.HostPath.Path),
+			severity, "Replace the hostPath volume with a ConfigMap, Secret, emptyDir or PersistentVolume, or mount it read-only.", container))
+	}
+	if hostPaths == 0 {
+		rec.Pass("HK-028")
+	}
+}
@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHostPathSeverity(t *testing.T) {
+	tests := []struct {
+		path     string
+		readOnly bool
+		want     Severity
+	}{
+		{"/var/run/docker.sock", true, SeverityCritical},
+		{"/", false, SeverityCritical},
+		{"/etc/kubernetes/", true, SeverityHigh},
+		{"/var/lib/kubelet/pki", false, SeverityCritical},
+		{"/data/cache", false, SeverityMedium},
+		{"/data/cache", true, SeverityLow},
+		{"/etcd-backup", true, SeverityLow},
+	}
+
+	for _, tt := range tests {
+		if got, _ := hostPathSeverity(tt.path, tt.readOnly); got != tt.want {
+			t.Errorf("hostPathSeverity(%q, %v) = %s, want %s", tt.path, tt.readOnly, got, tt.want)
+		}
+	}
+}
+
+func TestHostAccessChecks(t *testing.T) {
+	hostPath := func(name, path string) corev1.Volume {
+		return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: path}}}
+	}
+
+	snap := &k8s.Snapshot{Pods: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "monitoring"},
+		Spec: corev1.PodSpec{
+			HostNetwork: true,
+			HostPID:     true,
+			Containers: []corev1.Container{
+				{
+					Name:  "collector",
+					Ports: []corev1.ContainerPort{{ContainerPort: 9100, HostPort: 9100, Protocol: corev1.ProtocolTCP}},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "etc", MountPath: "/host/etc", ReadOnly: true},
+						{Name: "docker", MountPath: "/var/run/docker.sock", ReadOnly: true},
+					},
+				},
+				{
+					Name:         "shipper",
+					VolumeMounts: []corev1.VolumeMount{{Name: "etc", MountPath: "/host/etc"}},
+				},
+			},
+			Volumes: []corev1.Volume{hostPath("etc", "/etc"), hostPath("docker", "/var/run/docker.sock")},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: "ingress"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "envoy",
+			Ports: []corev1.ContainerPort{{ContainerPort: 8080, HostPort: 80, Protocol: corev1.ProtocolTCP}},
+		}}},
+	}}}
+
+	rec := NewRecorder()
+	if err := (&PodScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The agent's host port comes with hostNetwork and is only reported as HK-024
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-024": 1, "HK-025": 1, "HK-026": 0, "HK-027": 1, "HK-028": 2})
+	for _, issue := range rec.Issues() {
+		if issue.ID == "HK-027" && issue.Resource != "proxy" {
+			t.Errorf("unexpected HK-027 issue: %+v", issue)
+		}
+	}
+
+	for _, issue := range rec.Issues() {
+		if issue.ID != "HK-028" {
+			continue
+		}
+		// The shipper mounts /etc writable, so read-only in collector does not help
+		if issue.Severity != SeverityCritical {
+			t.Errorf("expected critical hostPath issue, got %s: %s", issue.Severity, issue.Description)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultRegistry is the registry implied by image references without one
+const defaultRegistry = "docker.io"
+
+// imageRef is a parsed container image reference
+type imageRef struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// parseImage splits an image reference such as
+// "ghcr.io/org/app:1.2@sha256:..." into its parts, applying Docker's
+// defaults for the registry
+func parseImage(image string) imageRef {
+	var ref imageRef
+
+	name := image
+	if at := strings.Index(name, "@"); at >= 0 {
+		ref.Digest = name[at+1:]
+		name = name[:at]
+	}
+
+	// A colon after the last slash separates the tag; earlier colons belong to a registry port
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		ref.Tag = name[colon+1:]
+		name = name[:colon]
+	}
+
+	ref.Registry = defaultRegistry
+	if slash := strings.Index(name, "/"); slash >= 0 {
+		first := name[:slash]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry = first
+			name = name[slash+1:]
+		}
+	}
+	ref.Repository = name
+	return ref
+}
+
+// mutable reports whether the reference can point at different content over time
+func (r imageRef) mutable() bool {
+	return r.Digest == "" && (r.Tag == "" || r.Tag == "latest")
+}
+
+// ImageScanner audits the container images referenced by workloads
+type ImageScanner struct {
+	// AllowedRegistries lists trusted registries or registry/path prefixes.
+	// When empty the registry check is skipped.
+	AllowedRegistries []string
+}
+
+// Name returns the scanner identifier
+func (s *ImageScanner) Name() string {
+	return "images"
+}
+
+// Resources returns the resources the image scanner reads
+func (s *ImageScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{k8s.ResourcePods}, k8s.WorkloadResources...)
+}
+
+// allowed reports whether an image comes from an allowlisted registry
+func (s *ImageScanner) allowed(ref imageRef) bool {
+	full := ref.Registry + "/" + ref.Repository
+	for _, entry := range s.AllowedRegistries {
+		entry = strings.TrimSuffix(entry, "/")
+		if ref.Registry == entry || strings.HasPrefix(full, entry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan audits every container image, reporting once per workload and container
+func (s *ImageScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	// Replicas of the same workload share their containers; report them once
+	seen := map[string]bool{}
+
+	for i := range snap.Pods {
+		pod := &snap.Pods[i]
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		workload := snap.WorkloadFor(pod)
+		for _, container := range podContainers(&pod.Spec) {
+			key := fmt.Sprintf("%s/%s/%s/%s", workload.Namespace, workload.Kind, workload.Name, container.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			s.checkContainer(rec, workload, container)
+		}
+	}
+	return nil
+}
+
+func (s *ImageScanner) checkContainer(rec *Recorder, workload k8s.WorkloadRef, container podContainer) {
+	ref := parseImage(container.Image)
+	resource := workload.Kind + "/" + workload.Name
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:            id,
+			Title:         title,
+			Description:   description,
+			Severity:      severity,
+			Resource:      resource,
+			Namespace:     workload.Namespace,
+			Remediation:   remediation,
+			Category:      CategoryImageSecurity,
+			Container:     container.Name,
+			ContainerType: container.Type,
+		}
+	}
+
+	// Check: latest or missing tag
+	if ref.mutable() {
+		rec.Fail(issue("HK-004", "Mutable Image Tag",
+			fmt.Sprintf("%s %s in namespace %s runs container %s with image %q, which uses the 'latest' tag or no tag at all", workload.Kind, workload.Name, workload.Namespace, container.Name, container.Image),
+			SeverityMedium, "Reference an explicit, immutable version tag or a digest."))
+	} else {
+		rec.Pass("HK-004")
+	}
+
+	// Check: pinned by digest
+	if ref.Digest == "" {
+		rec.Fail(issue("HK-005", "Image Not Pinned By Digest",
+			fmt.Sprintf("%s %s in namespace %s runs container %s with image %q, which is not pinned by digest", workload.Kind, workload.Name, workload.Namespace, container.Name, container.Image),
+			SeverityLow, "Pin the image with '@sha256:<digest>' so the deployed content cannot change."))
+	} else {
+		rec.Pass("HK-005")
+	}
+
+	// Check: registry allowlist
+	if len(s.AllowedRegistries) > 0 {
+		if !s.allowed(ref) {
+			rec.Fail(issue("HK-006", "Image From Untrusted Registry",
+				fmt.Sprintf("%s %s in namespace %s runs container %s with image %q from registry %s, which is not in the allowlist", workload.Kind, workload.Name, workload.Namespace, container.Name, container.Image, ref.Registry),
+				SeverityHigh, "Mirror the image into an approved registry and reference it from there."))
+		} else {
+			rec.Pass("HK-006")
+		}
+	}
+
+	// Check: pull policy consistent with the tag. A mutable tag with
+	// IfNotPresent or Never runs whatever version a node happened to cache.
+	pullPolicy := container.ImagePullPolicy
+	if pullPolicy == "" {
+		// Mirror the API server defaulting for objects that skipped it
+		pullPolicy = corev1.PullIfNotPresent
+		if ref.mutable() {
+			pullPolicy = corev1.PullAlways
+		}
+	}
+	if ref.mutable() && pullPolicy != corev1.PullAlways {
+		rec.Fail(issue("HK-007", "Inconsistent Image Pull Policy",
+			fmt.Sprintf("%s %s in namespace %s runs container %s with mutable image %q and imagePullPolicy %s, so nodes may run different versions", workload.Kind, workload.Name, workload.Namespace, container.Name, container.Image, pullPolicy),
+			SeverityMedium, "Pin the image to an immutable tag or digest, or set 'imagePullPolicy: Always'."))
+	} else {
+		rec.Pass("HK-007")
+	}
+}
@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  imageRef
+	}{
+		{"nginx", imageRef{Registry: "docker.io", Repository: "nginx"}},
+		{"nginx:1.25", imageRef{Registry: "docker.io", Repository: "nginx", Tag: "1.25"}},
+		{"library/nginx:latest", imageRef{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{"ghcr.io/org/app@sha256:abc", imageRef{Registry: "ghcr.io", Repository: "org/app", Digest: "sha256:abc"}},
+		{"registry.local:5000/app:2.0", imageRef{Registry: "registry.local:5000", Repository: "app", Tag: "2.0"}},
+		{"localhost/app", imageRef{Registry: "localhost", Repository: "app"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseImage(tt.image); got != tt.want {
+			t.Errorf("parseImage(%q) = %+v, want %+v", tt.image, got, tt.want)
+		}
+	}
+}
+
+func issueIDs(issues []Issue) map[string]int {
+	ids := map[string]int{}
+	for _, issue := range issues {
+		ids[issue.ID]++
+	}
+	return ids
+}
+
+// assertIssueCounts checks how many issues each rule reported
+func assertIssueCounts(t *testing.T, issues []Issue, want map[string]int) {
+	t.Helper()
+	ids := issueIDs(issues)
+	for id, count := range want {
+		if ids[id] != count {
+			t.Errorf("expected %d %s issues, got %d (%v)", count, id, ids[id], ids)
+		}
+	}
+}
+
+func TestImageScannerReportsOncePerWorkloadContainer(t *testing.T) {
+	controller := true
+	owned := func(name string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "shop",
+				OwnerReferences: []metav1.OwnerReference{{
+					Kind: "ReplicaSet", Name: "web-1", UID: "rs1", Controller: &controller,
+				}},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Image: "nginx:latest", ImagePullPolicy: corev1.PullIfNotPresent},
+				{Name: "proxy", Image: "ghcr.io/acme/proxy@sha256:abc"},
+			}},
+		}
+	}
+
+	snap := &k8s.Snapshot{
+		ReplicaSets: []appsv1.ReplicaSet{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "web-1", Namespace: "shop", UID: "rs1",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "d1", Controller: &controller}},
+			},
+		}},
+		Pods: []corev1.Pod{owned("web-1-a"), owned("web-1-b")},
+	}
+
+	scanner := &ImageScanner{AllowedRegistries: []string{"ghcr.io/acme"}}
+	rec := NewRecorder()
+	if err := scanner.Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-004": 1, "HK-005": 1, "HK-006": 1, "HK-007": 1})
+
+	for _, issue := range rec.Issues() {
+		if issue.Resource != "Deployment/web" || issue.Container != "app" || issue.Category != CategoryImageSecurity {
+			t.Errorf("expected findings on the app container of Deployment/web, got %+v", issue)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"syscall"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+)
+
+// nodeFile is a file or directory on a node whose permissions the CIS
+// benchmark constrains
+type nodeFile struct {
+	// Pattern is an absolute path on the node and may contain glob characters
+	Pattern string
+	// MaxMode is the most permissive mode allowed
+	MaxMode fs.FileMode
+	// Severity is raised for files holding credentials or private keys
+	Severity Severity
+	// SkipOwner leaves out files not owned by root, such as etcd's data directory
+	SkipOwner bool
+}
+
+// nodeFiles lists the worker and control plane files checked by the node
+// audit. Files that do not exist on a node are skipped.
+var nodeFiles = []nodeFile{
+	// Kubelet service, kubeconfigs and configuration (CIS 4.1)
+	{Pattern: "/etc/systemd/system/kubelet.service.d/*.conf", MaxMode: 0o600, Severity: SeverityMedium},
+	{Pattern: "/usr/lib/systemd/system/kubelet.service.d/*.conf", MaxMode: 0o600, Severity: SeverityMedium},
+	{Pattern: "/lib/systemd/system/kubelet.service", MaxMode: 0o600, Severity: SeverityMedium},
+	{Pattern: "/etc/kubernetes/kubelet.conf", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: "/etc/kubernetes/bootstrap-kubelet.conf", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: k8s.KubeletConfigPath, MaxMode: 0o600, Severity: SeverityMedium},
+	{Pattern: "/etc/kubernetes/pki/ca.crt", MaxMode: 0o644, Severity: SeverityMedium},
+	// Control plane manifests, kubeconfigs and PKI (CIS 1.1)
+	{Pattern: "/etc/kubernetes/manifests/*.yaml", MaxMode: 0o600, Severity: SeverityMedium},
+	{Pattern: "/etc/kubernetes/admin.conf", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: "/etc/kubernetes/super-admin.conf", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: "/etc/kubernetes/scheduler.conf", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: "/etc/kubernetes/controller-manager.conf", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: "/etc/kubernetes/pki", MaxMode: 0o755, Severity: SeverityMedium},
+	{Pattern: "/etc/kubernetes/pki/*.crt", MaxMode: 0o644, Severity: SeverityMedium},
+	{Pattern: "/etc/kubernetes/pki/*.key", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: "/etc/kubernetes/pki/etcd/*.key", MaxMode: 0o600, Severity: SeverityHigh},
+	{Pattern: "/var/lib/etcd", MaxMode: 0o700, Severity: SeverityHigh, SkipOwner: true},
+}
+
+// NodeFileScanner checks the permissions and ownership of Kubernetes files
+// on a node's filesystem, mounted at Root. It reads no API resources and is
+// run by 'hardena node-audit' rather than the cluster scan.
+type NodeFileScanner struct {
+	// Root is the mount point of the node's root filesystem, "/" on the node itself
+	Root string
+	// Node names the node in findings
+	Node string
+}
+
+// Name returns the scanner identifier
+func (s *NodeFileScanner) Name() string {
+	return "node-files"
+}
+
+// Resources returns nothing; the scanner reads the filesystem
+func (s *NodeFileScanner) Resources() []k8s.Resource {
+	return nil
+}
+
+// Scan runs the file permission and ownership checks. Paths are resolved
+// inside Root, so symlinks on the node cannot lead the audit to files of
+// the machine running it.
+func (s *NodeFileScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	seen := map[string]bool{}
+	for _, file := range nodeFiles {
+		dir, pattern := path.Split(file.Pattern)
+		hostDir, err := k8s.HostPath(s.Root, dir)
+		if err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(hostDir)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if ok, _ := path.Match(pattern, entry.Name()); !ok {
+				continue
+			}
+			nodePath := path.Join(dir, entry.Name())
+			if seen[nodePath] {
+				continue
+			}
+
+			// A matching symlink is judged by the file it points to
+			hostPath, err := k8s.HostPath(s.Root, nodePath)
+			if err != nil {
+				return err
+			}
+			info, err := os.Lstat(hostPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			seen[nodePath] = true
+			s.checkFile(rec, file, nodePath, info)
+		}
+	}
+	return nil
+}
+
+func (s *NodeFileScanner) checkFile(rec *Recorder, file nodeFile, nodePath string, info fs.FileInfo) {
+	rec.Scanned("File", "", nodePath)
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("%s on node %s %s", nodePath, s.Node, description),
+			Severity:    severity,
+			Resource:    "Node/" + s.Node + ":" + nodePath,
+			Remediation: remediation,
+			Category:    CategoryNodes,
+		}
+	}
+
+	// Check: permissions beyond the allowed mode
+	if mode := info.Mode().Perm(); mode&^file.MaxMode != 0 {
+		rec.Fail(issue("HK-064", "Permissive Node File Permissions",
+			fmt.Sprintf("has mode %04o, more permissive than %04o", mode, file.MaxMode),
+			file.Severity, fmt.Sprintf("Run 'chmod %o %s' on the node.", file.MaxMode, nodePath)))
+	} else {
+		rec.Pass("HK-064")
+	}
+
+	// Check: ownership by root
+	if file.SkipOwner {
+		return
+	}
+	if uid, gid, ok := fileOwner(info); ok {
+		if uid != 0 || gid != 0 {
+			rec.Fail(issue("HK-065", "Node File Not Owned By root",
+				fmt.Sprintf("is owned by %d:%d instead of root:root", uid, gid),
+				SeverityMedium, fmt.Sprintf("Run 'chown root:root %s' on the node.", nodePath)))
+		} else {
+			rec.Pass("HK-065")
+		}
+	}
+}
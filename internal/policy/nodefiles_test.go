@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+)
+
+func TestNodeFileScanner(t *testing.T) {
+	root := t.TempDir()
+	write := func(path string, mode os.FileMode) {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("test"), mode); err != nil {
+			t.Fatal(err)
+		}
+		// WriteFile is subject to the umask
+		if err := os.Chmod(full, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("etc/kubernetes/admin.conf", 0o644)
+	write("etc/kubernetes/pki/ca.crt", 0o644)
+	write("etc/kubernetes/pki/ca.key", 0o640)
+	write("etc/kubernetes/manifests/kube-apiserver.yaml", 0o600)
+
+	rec := NewRecorder()
+	scanner := &NodeFileScanner{Root: root, Node: "worker-1"}
+	if err := scanner.Scan(context.Background(), &k8s.Snapshot{}, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reported := map[string]Severity{}
+	for _, issue := range rec.Issues() {
+		if issue.ID == "HK-064" {
+			reported[issue.Resource] = issue.Severity
+		}
+	}
+	want := map[string]Severity{
+		"Node/worker-1:/etc/kubernetes/admin.conf":                    SeverityHigh,
+		"Node/worker-1:/etc/kubernetes/pki/ca.key":                    SeverityHigh,
+		"Node/worker-1:/etc/kubernetes/pki":                           "",
+		"Node/worker-1:/etc/kubernetes/pki/ca.crt":                    "",
+		"Node/worker-1:/etc/kubernetes/manifests/kube-apiserver.yaml": "",
+	}
+	for path, severity := range want {
+		if reported[path] != severity {
+			t.Errorf("%s: expected %q, got %q", path, severity, reported[path])
+		}
+	}
+	if stats := rec.rules["HK-064"]; stats == nil || stats.Passed != 3 || stats.Failed != 2 {
+		t.Errorf("expected 3 passed and 2 failed permission checks, got %+v", stats)
+	}
+}
+
+func TestNodeFileScannerResolvesSymlinksInRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "etc/kubernetes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "admin.conf"), []byte("test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A world-readable file on the auditing machine, outside the node's root
+	outside := filepath.Join(t.TempDir(), "admin.conf")
+	if err := os.WriteFile(outside, []byte("test"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(outside, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		// Absolute targets are resolved below root, where this one does not exist
+		"etc/kubernetes/admin.conf": outside,
+		// Relative targets reach root/admin.conf, and ".." stops at root
+		"etc/kubernetes/scheduler.conf":          "../../admin.conf",
+		"etc/kubernetes/controller-manager.conf": "../../../../../../admin.conf",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := NewRecorder()
+	scanner := &NodeFileScanner{Root: root, Node: "worker-1"}
+	if err := scanner.Scan(context.Background(), &k8s.Snapshot{}, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-064": 0})
+	if stats := rec.rules["HK-064"]; stats == nil || stats.Passed != 2 {
+		t.Errorf("expected 2 link targets inside root to be checked, got %+v", stats)
+	}
+}
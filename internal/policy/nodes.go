@@ -0,0 +1,266 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// maxKubeletSkew is how many minor versions a kubelet may trail the newest
+// kubelet in the cluster before it is reported
+const maxKubeletSkew = 1
+
+// maxServerSkew is how many minor versions a kubelet may trail the API
+// server under the Kubernetes version skew policy
+const maxServerSkew = 3
+
+// minimumKernel is the oldest Linux LTS kernel line still receiving fixes
+var minimumKernel = version.MustParseGeneric("5.10")
+
+// minimumRuntimes lists the oldest supported release of each container runtime
+var minimumRuntimes = map[string]*version.Version{
+	"containerd": version.MustParseGeneric("1.7"),
+}
+
+// genericNodeRoles are node-role labels that do not mark a dedicated pool
+var genericNodeRoles = map[string]bool{
+	"":       true,
+	"worker": true,
+	"node":   true,
+	"agent":  true,
+}
+
+// nodeRoleLabelPrefix marks a node's role, e.g. node-role.kubernetes.io/control-plane
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// dedicatedPool returns the label that marks a node as part of a dedicated
+// pool, or an empty string for general purpose nodes
+func dedicatedPool(node *corev1.Node) string {
+	if value, ok := node.Labels["dedicated"]; ok {
+		return "dedicated=" + value
+	}
+	for key := range node.Labels {
+		if role, ok := strings.CutPrefix(key, nodeRoleLabelPrefix); ok && !genericNodeRoles[role] {
+			return key
+		}
+	}
+	return ""
+}
+
+// NodeScanner audits the versions and scheduling setup that nodes report
+// through the API, without needing access to the hosts
+type NodeScanner struct{}
+
+// Name returns the scanner identifier
+func (s *NodeScanner) Name() string {
+	return "nodes"
+}
+
+// Resources returns the resources the node scanner reads
+func (s *NodeScanner) Resources() []k8s.Resource {
+	return []k8s.Resource{k8s.ResourceNodes}
+}
+
+// Scan runs the node checks
+func (s *NodeScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	// Kubelet skew is measured against the newest kubelet in the cluster
+	var newest *version.Version
+	for i := range snap.Nodes {
+		v, err := version.ParseGeneric(snap.Nodes[i].Status.NodeInfo.KubeletVersion)
+		if err == nil && (newest == nil || newest.LessThan(v)) {
+			newest = v
+		}
+	}
+
+	// Snapshots without a server version, such as older archives, only
+	// compare kubelets with each other
+	var server *version.Version
+	if snap.ServerVersion != "" {
+		v, err := version.ParseGeneric(snap.ServerVersion)
+		if err != nil {
+			return fmt.Errorf("failed to parse server version %q: %w", snap.ServerVersion, err)
+		}
+		server = v
+	}
+
+	for i := range snap.Nodes {
+		s.checkNode(rec, &snap.Nodes[i], newest, server)
+	}
+	return nil
+}
+
+func (s *NodeScanner) checkNode(rec *Recorder, node *corev1.Node, newest, server *version.Version) {
+	rec.Scanned("Node", "", node.Name)
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("Node %s %s", node.Name, description),
+			Severity:    severity,
+			Resource:    "Node/" + node.Name,
+			Remediation: remediation,
+			Category:    CategoryNodes,
+		}
+	}
+	info := node.Status.NodeInfo
+
+	// Check: kubelets outside the supported skew from the API server, or
+	// lagging behind the rest of the cluster
+	if kubelet, err := version.ParseGeneric(info.KubeletVersion); err == nil && newest != nil {
+		switch {
+		case server != nil && kubelet.Major() == server.Major() && kubelet.Minor() > server.Minor():
+			rec.Fail(issue("HK-048", "Kubelet Newer Than API Server",
+				fmt.Sprintf("runs kubelet %s, newer than API server %s, which the version skew policy does not support", info.KubeletVersion, server),
+				SeverityHigh, "Upgrade the control plane before its nodes, or roll the node back to a kubelet matching the API server."))
+		case server != nil && kubelet.Major() == server.Major() && server.Minor()-kubelet.Minor() > maxServerSkew:
+			rec.Fail(issue("HK-048", "Outdated Kubelet",
+				fmt.Sprintf("runs kubelet %s, more than %d minor versions behind API server %s, which the version skew policy does not support", info.KubeletVersion, maxServerSkew, server),
+				SeverityHigh, "Upgrade the node, or replace it with one built from the current node image."))
+		case kubelet.Major() == newest.Major() && newest.Minor()-kubelet.Minor() > maxKubeletSkew:
+			rec.Fail(issue("HK-048", "Outdated Kubelet",
+				fmt.Sprintf("runs kubelet %s while other nodes run %s", info.KubeletVersion, newest),
+				SeverityMedium, "Upgrade the node, or replace it with one built from the current node image."))
+		default:
+			rec.Pass("HK-048")
+		}
+	}
+
+	// Check: container runtimes that are unsupported or past end of life
+	runtimeName, runtimeVersion, _ := strings.Cut(info.ContainerRuntimeVersion, "://")
+	if runtimeName != "" {
+		v, err := version.ParseGeneric(runtimeVersion)
+		minimum, known := minimumRuntimes[runtimeName]
+		kubelet, kubeletErr := version.ParseGeneric(info.KubeletVersion)
+		switch {
+		case runtimeName == "docker" && kubeletErr == nil && kubelet.Major() == 1 && kubelet.Minor() < 24:
+			rec.Fail(issue("HK-049", "Unsupported Container Runtime",
+				fmt.Sprintf("runs %s through dockershim, which was removed in Kubernetes 1.24", info.ContainerRuntimeVersion),
+				SeverityMedium, "Migrate the node to containerd or CRI-O before upgrading it."))
+		case runtimeName == "docker":
+			// Newer kubelets reach Docker through the cri-dockerd adapter
+			rec.Fail(issue("HK-049", "Docker Container Runtime",
+				fmt.Sprintf("runs %s through cri-dockerd, an extra component outside Kubernetes that must be patched alongside Docker Engine", info.ContainerRuntimeVersion),
+				SeverityLow, "Keep cri-dockerd and Docker Engine up to date, or migrate the node to containerd or CRI-O."))
+		case known && err == nil && v.LessThan(minimum):
+			rec.Fail(issue("HK-049", "Outdated Container Runtime",
+				fmt.Sprintf("runs %s, older than the oldest supported release %s", info.ContainerRuntimeVersion, minimum),
+				SeverityMedium, fmt.Sprintf("Upgrade %s to %s or later.", runtimeName, minimum)))
+		case runtimeName == "cri-o" && err == nil && kubeletErr == nil && v.Minor() < kubelet.Minor():
+			// CRI-O releases track Kubernetes minor versions
+			rec.Fail(issue("HK-049", "Outdated Container Runtime",
+				fmt.Sprintf("runs %s with kubelet %s; CRI-O should match the kubelet minor version", info.ContainerRuntimeVersion, info.KubeletVersion),
+				SeverityMedium, "Upgrade CRI-O to the release matching the kubelet."))
+		default:
+			rec.Pass("HK-049")
+		}
+	}
+
+	// Check: kernels that no longer receive security fixes
+	if kernel, err := version.ParseGeneric(info.KernelVersion); err == nil && info.OperatingSystem != "windows" {
+		if kernel.LessThan(minimumKernel) {
+			rec.Fail(issue("HK-050", "Outdated Kernel",
+				fmt.Sprintf("runs kernel %s, older than %s, the oldest maintained LTS line", info.KernelVersion, minimumKernel),
+				SeverityMedium, "Move the node to an OS image with a maintained kernel."))
+		} else {
+			rec.Pass("HK-050")
+		}
+	}
+
+	// Check: dedicated pools need taints, or any workload can land on them
+	if pool := dedicatedPool(node); pool != "" {
+		if len(node.Spec.Taints) == 0 {
+			rec.Fail(issue("HK-051", "Dedicated Node Without Taints",
+				fmt.Sprintf("is labeled %s but has no taints, so any pod can be scheduled onto it", pool),
+				SeverityMedium, "Taint the node pool with NoSchedule and give only its intended workloads a matching toleration."))
+		} else {
+			rec.Pass("HK-051")
+		}
+	}
+}
+
+// KubeletScanner audits each kubelet's running configuration, read through
+// the nodes/proxy configz endpoint
+type KubeletScanner struct{}
+
+// Name returns the scanner identifier
+func (s *KubeletScanner) Name() string {
+	return "kubelet"
+}
+
+// Resources returns the resources the kubelet scanner reads
+func (s *KubeletScanner) Resources() []k8s.Resource {
+	return []k8s.Resource{k8s.ResourceKubeletConfigs}
+}
+
+// Scan runs the kubelet configuration checks
+func (s *KubeletScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	names := make([]string, 0, len(snap.KubeletConfigs))
+	for name := range snap.KubeletConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		config := snap.KubeletConfigs[name]
+		rec.Scanned("Node", "", name)
+		issue := func(id, title, description string, severity Severity, remediation string) Issue {
+			return Issue{
+				ID:          id,
+				Title:       title,
+				Description: fmt.Sprintf("The kubelet on node %s %s", name, description),
+				Severity:    severity,
+				Resource:    "Node/" + name,
+				Remediation: remediation,
+				Category:    CategoryNodes,
+			}
+		}
+		alwaysAllow := config.Authorization.Mode == "AlwaysAllow"
+
+		// Check: anonymous requests; combined with AlwaysAllow anyone reaching
+		// port 10250 can run commands in every pod on the node
+		if anonymous := config.Authentication.Anonymous.Enabled; anonymous != nil && *anonymous {
+			severity := SeverityHigh
+			if alwaysAllow {
+				severity = SeverityCritical
+			}
+			rec.Fail(issue("HK-052", "Kubelet Anonymous Authentication Enabled",
+				"accepts anonymous requests",
+				severity, "Set 'authentication.anonymous.enabled: false' in the kubelet configuration."))
+		} else {
+			rec.Pass("HK-052")
+		}
+
+		// Check: authorization mode
+		if alwaysAllow {
+			rec.Fail(issue("HK-053", "Kubelet Authorization AlwaysAllow",
+				"authorizes every authenticated request",
+				SeverityCritical, "Set 'authorization.mode: Webhook' so the API server authorizes kubelet requests."))
+		} else {
+			rec.Pass("HK-053")
+		}
+
+		// Check: the unauthenticated read-only port
+		if port := config.ReadOnlyPort; port != nil && *port > 0 {
+			rec.Fail(issue("HK-054", "Kubelet Read-Only Port Enabled",
+				fmt.Sprintf("serves the unauthenticated read-only API on port %d", *port),
+				SeverityMedium, "Set 'readOnlyPort: 0' in the kubelet configuration."))
+		} else {
+			rec.Pass("HK-054")
+		}
+
+		// Check: kernel tunables the kubelet may silently change
+		if protect := config.ProtectKernelDefaults; protect == nil || !*protect {
+			rec.Fail(issue("HK-055", "Kubelet Does Not Protect Kernel Defaults",
+				"may change kernel tunables that differ from its defaults",
+				SeverityLow, "Set 'protectKernelDefaults: true' and configure the kernel parameters the kubelet expects."))
+		} else {
+			rec.Pass("HK-055")
+		}
+	}
+	return nil
+}
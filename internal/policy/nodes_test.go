@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeScanner(t *testing.T) {
+	node := func(name, kubelet, runtime, kernel string, labels map[string]string, taints ...corev1.Taint) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Taints: taints},
+			Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{
+				KubeletVersion:          kubelet,
+				ContainerRuntimeVersion: runtime,
+				KernelVersion:           kernel,
+				OperatingSystem:         "linux",
+			}},
+		}
+	}
+	gpu := map[string]string{"node-role.kubernetes.io/gpu": ""}
+	noSchedule := corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+
+	snap := &k8s.Snapshot{Nodes: []corev1.Node{
+		node("current", "v1.31.2", "containerd://1.7.22", "6.1.109-118.189.amzn2023.x86_64", gpu, noSchedule),
+		node("stale", "v1.29.8", "containerd://1.6.33", "5.4.0-1103-aws", nil),
+		node("docker", "v1.31.2", "docker://20.10.7", "5.15.0-1034-aws", gpu),
+		node("crio", "v1.31.2", "cri-o://1.30.4", "5.15.0", map[string]string{"node-role.kubernetes.io/worker": ""}),
+	}}
+
+	rec := NewRecorder()
+	if err := (&NodeScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-048": 1, "HK-049": 3, "HK-050": 1, "HK-051": 1})
+	for _, issue := range rec.Issues() {
+		if issue.ID == "HK-049" && issue.Resource == "Node/docker" && issue.Severity != SeverityLow {
+			t.Errorf("expected Docker through cri-dockerd to be low severity, got %s", issue.Severity)
+		}
+		if issue.ID == "HK-051" && issue.Resource != "Node/docker" {
+			t.Errorf("expected only the untainted GPU node to be reported, got %s", issue.Resource)
+		}
+	}
+}
+
+func TestNodeScannerComparesKubeletsWithServer(t *testing.T) {
+	node := func(name, kubelet string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubelet}},
+		}
+	}
+
+	tests := map[string]struct {
+		server string
+		nodes  []corev1.Node
+		want   string
+	}{
+		"all kubelets too old": {
+			server: "v1.30.4",
+			nodes:  []corev1.Node{node("a", "v1.26.3"), node("b", "v1.26.3")},
+			want:   "Outdated Kubelet",
+		},
+		"kubelet newer than server": {
+			server: "v1.30.4",
+			nodes:  []corev1.Node{node("a", "v1.31.0"), node("b", "v1.31.0")},
+			want:   "Kubelet Newer Than API Server",
+		},
+		"within skew": {
+			server: "v1.30.4",
+			nodes:  []corev1.Node{node("a", "v1.30.4"), node("b", "v1.30.1")},
+		},
+	}
+	for name, tt := range tests {
+		rec := NewRecorder()
+		snap := &k8s.Snapshot{ServerVersion: tt.server, Nodes: tt.nodes}
+		if err := (&NodeScanner{}).Scan(context.Background(), snap, rec); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		issues := rec.Issues()
+		if tt.want == "" {
+			if len(issues) != 0 {
+				t.Errorf("%s: expected no issues, got %+v", name, issues)
+			}
+			continue
+		}
+		if len(issues) != len(tt.nodes) || issues[0].ID != "HK-048" || issues[0].Title != tt.want {
+			t.Errorf("%s: expected every node to be reported as %q, got %+v", name, tt.want, issues)
+		}
+	}
+}
+
+func TestKubeletScanner(t *testing.T) {
+	enabled, disabled := true, false
+	var readOnlyPort int32 = 10255
+
+	open := k8s.KubeletConfig{ReadOnlyPort: &readOnlyPort}
+	open.Authentication.Anonymous.Enabled = &enabled
+	open.Authorization.Mode = "AlwaysAllow"
+
+	hardened := k8s.KubeletConfig{ProtectKernelDefaults: &enabled}
+	hardened.Authentication.Anonymous.Enabled = &disabled
+	hardened.Authorization.Mode = "Webhook"
+
+	snap := &k8s.Snapshot{KubeletConfigs: map[string]k8s.KubeletConfig{"open": open, "hardened": hardened}}
+
+	rec := NewRecorder()
+	if err := (&KubeletScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-052": 1, "HK-053": 1, "HK-054": 1, "HK-055": 1})
+	for _, issue := range rec.Issues() {
+		if issue.Resource != "Node/open" {
+			t.Errorf("expected only the open kubelet to be reported, got %s", issue.Resource)
+		}
+		if issue.ID == "HK-052" && issue.Severity != SeverityCritical {
+			t.Errorf("expected anonymous auth with AlwaysAllow to be %s, got %s", SeverityCritical, issue.Severity)
+		}
+	}
+}
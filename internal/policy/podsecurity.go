@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dangerousCapabilities maps capabilities that weaken container isolation to
+// the severity of adding them
+var dangerousCapabilities = map[string]Severity{
+	"ALL":        SeverityCritical,
+	"SYS_ADMIN":  SeverityCritical,
+	"SYS_MODULE": SeverityCritical,
+	"SYS_PTRACE": SeverityHigh,
+	"NET_ADMIN":  SeverityHigh,
+	"NET_RAW":    SeverityHigh,
+}
+
+// Legacy annotations that predate the securityContext seccomp and AppArmor fields
+const (
+	seccompPodAnnotation        = "seccomp.security.alpha.kubernetes.io/pod"
+	seccompContainerAnnotation  = "container.seccomp.security.alpha.kubernetes.io/"
+	appArmorContainerAnnotation = "container.apparmor.security.beta.kubernetes.io/"
+	annotationProfileUnconfined = "unconfined"
+	annotationLocalhostPrefix   = "localhost/"
+)
+
+// normalizeCapability accepts both "NET_RAW" and "CAP_NET_RAW" spellings
+func normalizeCapability(capability corev1.Capability) string {
+	return strings.TrimPrefix(strings.ToUpper(string(capability)), "CAP_")
+}
+
+// profileFromAnnotation converts a legacy seccomp or AppArmor annotation value
+func profileFromAnnotation(value string) string {
+	switch {
+	case value == annotationProfileUnconfined:
+		return "Unconfined"
+	case strings.HasPrefix(value, annotationLocalhostPrefix):
+		return "Localhost"
+	case value != "":
+		return "RuntimeDefault"
+	}
+	return ""
+}
+
+// effectiveSeccomp returns the seccomp profile type applied to a container,
+// or an empty string when none is set. Container settings override the pod.
+func effectiveSeccomp(pod *corev1.Pod, container corev1.Container) string {
+	if sc := container.SecurityContext; sc != nil && sc.SeccompProfile != nil {
+		return string(sc.SeccompProfile.Type)
+	}
+	if value, ok := pod.Annotations[seccompContainerAnnotation+container.Name]; ok {
+		return profileFromAnnotation(value)
+	}
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.SeccompProfile != nil {
+		return string(sc.SeccompProfile.Type)
+	}
+	return profileFromAnnotation(pod.Annotations[seccompPodAnnotation])
+}
+
+// effectiveAppArmor returns the AppArmor profile type applied to a container,
+// or an empty string when the runtime default applies
+func effectiveAppArmor(pod *corev1.Pod, container corev1.Container) string {
+	if sc := container.SecurityContext; sc != nil && sc.AppArmorProfile != nil {
+		return string(sc.AppArmorProfile.Type)
+	}
+	if value, ok := pod.Annotations[appArmorContainerAnnotation+container.Name]; ok {
+		return profileFromAnnotation(value)
+	}
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.AppArmorProfile != nil {
+		return string(sc.AppArmorProfile.Type)
+	}
+	return ""
+}
+
+// effectiveRunAsUser returns the UID a container runs as, or nil when the
+// image decides
+func effectiveRunAsUser(pod *corev1.Pod, container corev1.Container) *int64 {
+	if sc := container.SecurityContext; sc != nil && sc.RunAsUser != nil {
+		return sc.RunAsUser
+	}
+	if sc := pod.Spec.SecurityContext; sc != nil {
+		return sc.RunAsUser
+	}
+	return nil
+}
+
+// checkContainerHardening covers privilege escalation, capabilities, seccomp,
+// AppArmor and the UID of a single container
+func (p *PodScanner) checkContainerHardening(rec *Recorder, pod *corev1.Pod, c podContainer) {
+	container := c.Container
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:            id,
+			Title:         title,
+			Description:   fmt.Sprintf("Pod %s in namespace %s %s: %s", pod.Name, pod.Namespace, description, container.Name),
+			Severity:      severity,
+			Resource:      pod.Name,
+			Namespace:     pod.Namespace,
+			Remediation:   remediation,
+			Category:      CategoryPodSecurity,
+			Container:     container.Name,
+			ContainerType: c.Type,
+		}
+	}
+	sc := container.SecurityContext
+
+	// Check: allowPrivilegeEscalation defaults to true unless explicitly disabled
+	if sc == nil || sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		rec.Fail(issue("HK-018", "Privilege Escalation Allowed",
+			"does not set 'allowPrivilegeEscalation: false' for a container",
+			SeverityMedium, "Set 'allowPrivilegeEscalation: false' in the container securityContext."))
+	} else {
+		rec.Pass("HK-018")
+	}
+
+	// Check: all capabilities dropped
+	droppedAll := false
+	var added []string
+	addedSeverity := SeverityInfo
+	if sc != nil && sc.Capabilities != nil {
+		for _, capability := range sc.Capabilities.Drop {
+			if normalizeCapability(capability) == "ALL" {
+				droppedAll = true
+			}
+		}
+		for _, capability := range sc.Capabilities.Add {
+			name := normalizeCapability(capability)
+			if severity, ok := dangerousCapabilities[name]; ok {
+				added = append(added, name)
+				if severityWeight[severity] > severityWeight[addedSeverity] {
+					addedSeverity = severity
+				}
+			}
+		}
+	}
+	if !droppedAll {
+		rec.Fail(issue("HK-019", "Capabilities Not Dropped",
+			"does not drop ALL capabilities for a container",
+			SeverityMedium, "Add 'drop: [\"ALL\"]' to securityContext.capabilities and add back only what is required."))
+	} else {
+		rec.Pass("HK-019")
+	}
+
+	// Check: dangerous capabilities added
+	if len(added) > 0 {
+		sort.Strings(added)
+		rec.Fail(issue("HK-020", "Dangerous Capabilities Added",
+			fmt.Sprintf("adds %s to a container", strings.Join(added, ", ")),
+			addedSeverity, "Remove the capabilities from securityContext.capabilities.add."))
+	} else {
+		rec.Pass("HK-020")
+	}
+
+	// Check: seccomp profile
+	switch effectiveSeccomp(pod, container) {
+	case "":
+		rec.Fail(issue("HK-021", "Seccomp Profile Missing Or Unconfined",
+			"does not set a seccomp profile for a container",
+			SeverityMedium, "Set 'seccompProfile: {type: RuntimeDefault}' in the pod or container securityContext."))
+	case string(corev1.SeccompProfileTypeUnconfined):
+		rec.Fail(issue("HK-021", "Seccomp Profile Missing Or Unconfined",
+			"runs a container with the Unconfined seccomp profile",
+			SeverityHigh, "Set 'seccompProfile: {type: RuntimeDefault}' in the pod or container securityContext."))
+	default:
+		rec.Pass("HK-021")
+	}
+
+	// Check: AppArmor explicitly disabled
+	if effectiveAppArmor(pod, container) == string(corev1.AppArmorProfileTypeUnconfined) {
+		rec.Fail(issue("HK-022", "AppArmor Unconfined",
+			"runs a container with the Unconfined AppArmor profile",
+			SeverityHigh, "Use 'appArmorProfile: {type: RuntimeDefault}' or a Localhost profile instead of Unconfined."))
+	} else {
+		rec.Pass("HK-022")
+	}
+
+	// Check: explicit root UID, which runAsNonRoot would reject at admission
+	if uid := effectiveRunAsUser(pod, container); uid != nil && *uid == 0 {
+		rec.Fail(issue("HK-023", "Container Runs As UID 0",
+			"sets 'runAsUser: 0' for a container",
+			SeverityHigh, "Set 'runAsUser' to a non-zero UID."))
+	} else {
+		rec.Pass("HK-023")
+	}
+}
@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestContainerHardeningChecks(t *testing.T) {
+	no := false
+	root := int64(0)
+	user := int64(1000)
+
+	hardened := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &no,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+
+	snap := &k8s.Snapshot{Pods: []corev1.Pod{
+		{
+			// Pod-level seccomp and UID are inherited by containers that do not override them
+			ObjectMeta: metav1.ObjectMeta{Name: "hardened", Namespace: "shop"},
+			Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser:      &root,
+					SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				},
+				Containers: []corev1.Container{{
+					Name: "app",
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: &no,
+						Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						RunAsUser:                &user,
+					},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "loose", Namespace: "shop",
+				Annotations: map[string]string{"container.apparmor.security.beta.kubernetes.io/app": "unconfined"},
+			},
+			Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser:      &root,
+					SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				},
+				Containers: []corev1.Container{
+					{
+						Name: "app",
+						SecurityContext: &corev1.SecurityContext{
+							Capabilities:   &corev1.Capabilities{Add: []corev1.Capability{"CAP_SYS_ADMIN", "NET_RAW", "CHOWN"}},
+							SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined},
+						},
+					},
+					{Name: "sidecar", SecurityContext: hardened},
+				},
+			},
+		},
+	}}
+
+	rec := NewRecorder()
+	if err := (&PodScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]Issue{}
+	for _, issue := range rec.Issues() {
+		got[issue.Resource+"/"+issue.Container+"/"+issue.ID] = issue
+	}
+
+	for _, key := range []string{"hardened/app/HK-018", "hardened/app/HK-019", "hardened/app/HK-021", "hardened/app/HK-023", "loose/sidecar/HK-018", "loose/sidecar/HK-021"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("unexpected issue %s", key)
+		}
+	}
+
+	expected := map[string]Severity{
+		"loose/app/HK-018":     SeverityMedium,
+		"loose/app/HK-019":     SeverityMedium,
+		"loose/app/HK-020":     SeverityCritical,
+		"loose/app/HK-021":     SeverityHigh,
+		"loose/app/HK-022":     SeverityHigh,
+		"loose/app/HK-023":     SeverityHigh,
+		"loose/sidecar/HK-023": SeverityHigh,
+	}
+	for key, severity := range expected {
+		issue, ok := got[key]
+		if !ok {
+			t.Errorf("expected issue %s", key)
+			continue
+		}
+		if issue.Severity != severity {
+			t.Errorf("%s: expected severity %s, got %s", key, severity, issue.Severity)
+		}
+	}
+}
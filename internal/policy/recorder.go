@@ -0,0 +1,64 @@
+package policy
+
+// Recorder collects what a scanner inspected and the outcome of every check
+// it ran. Each scanner gets its own Recorder, so no locking is needed; the
+// engine merges them once all scanners have finished.
+type Recorder struct {
+	issues  []Issue
+	objects map[objectKey]bool
+	rules   map[string]*RuleStats
+}
+
+// objectKey identifies an evaluated object across scanners
+type objectKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		objects: map[objectKey]bool{},
+		rules:   map[string]*RuleStats{},
+	}
+}
+
+// Scanned records that an object was evaluated
+func (r *Recorder) Scanned(kind, namespace, name string) {
+	r.objects[objectKey{kind: kind, namespace: namespace, name: name}] = true
+}
+
+// Pass records a check that found nothing wrong
+func (r *Recorder) Pass(ruleID string) {
+	r.rule(ruleID).Passed++
+}
+
+// Fail records a failed check and the issue describing it
+func (r *Recorder) Fail(issue Issue) {
+	r.rule(issue.ID).Failed++
+	r.issues = append(r.issues, issue)
+}
+
+// Issues returns the issues recorded so far
+func (r *Recorder) Issues() []Issue {
+	return r.issues
+}
+
+func (r *Recorder) rule(id string) *RuleStats {
+	stats, ok := r.rules[id]
+	if !ok {
+		stats = &RuleStats{ID: id}
+		r.rules[id] = stats
+	}
+	return stats
+}
+
+// checks returns the total number of checks recorded
+func (r *Recorder) checks() int {
+	total := 0
+	for _, stats := range r.rules {
+		total += stats.Passed + stats.Failed
+	}
+	return total
+}
@@ -0,0 +1,233 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// batchWorkloads run to completion and are not expected to have probes
+var batchWorkloads = map[string]bool{
+	"Job":     true,
+	"CronJob": true,
+}
+
+// ReliabilityScanner audits probes, replica counts and disruption budgets.
+// It is opt-in because availability is a production-readiness concern
+// rather than a security finding.
+type ReliabilityScanner struct{}
+
+// Name returns the scanner identifier
+func (s *ReliabilityScanner) Name() string {
+	return "reliability"
+}
+
+// Resources returns the resources the reliability scanner reads
+func (s *ReliabilityScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{
+		k8s.ResourcePods,
+		k8s.ResourceServices,
+		k8s.ResourcePodDisruptionBudgets,
+	}, k8s.WorkloadResources...)
+}
+
+// Scan runs the reliability checks
+func (s *ReliabilityScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	// Replicas of the same workload share their spec; report them once
+	seen := map[string]bool{}
+	for i := range snap.Pods {
+		pod := &snap.Pods[i]
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		workload := snap.WorkloadFor(pod)
+		key := fmt.Sprintf("%s/%s/%s", workload.Namespace, workload.Kind, workload.Name)
+		if seen[key] || batchWorkloads[workload.Kind] {
+			continue
+		}
+		seen[key] = true
+		s.checkProbes(rec, workload, pod)
+	}
+
+	for i := range snap.Deployments {
+		d := &snap.Deployments[i]
+		rec.Scanned("Deployment", d.Namespace, d.Name)
+		s.checkReplicas(snap, rec, d)
+		s.checkDisruptionBudget(snap, rec, "Deployment", d.ObjectMeta, d.Spec.Replicas, d.Spec.Template.Labels)
+	}
+	for i := range snap.StatefulSets {
+		sts := &snap.StatefulSets[i]
+		rec.Scanned("StatefulSet", sts.Namespace, sts.Name)
+		s.checkDisruptionBudget(snap, rec, "StatefulSet", sts.ObjectMeta, sts.Spec.Replicas, sts.Spec.Template.Labels)
+	}
+
+	for i := range snap.PodDisruptionBudgets {
+		pdb := &snap.PodDisruptionBudgets[i]
+		rec.Scanned("PodDisruptionBudget", pdb.Namespace, pdb.Name)
+		s.checkBudget(snap, rec, pdb)
+	}
+	return nil
+}
+
+func (s *ReliabilityScanner) checkProbes(rec *Recorder, workload k8s.WorkloadRef, pod *corev1.Pod) {
+	issue := func(id, title, description string, severity Severity, remediation, container string) Issue {
+		return Issue{
+			ID:            id,
+			Title:         title,
+			Description:   fmt.Sprintf("%s %s in namespace %s %s", workload.Kind, workload.Name, workload.Namespace, description),
+			Severity:      severity,
+			Resource:      workload.Kind + "/" + workload.Name,
+			Namespace:     workload.Namespace,
+			Remediation:   remediation,
+			Category:      CategoryReliability,
+			Container:     container,
+			ContainerType: ContainerTypeRegular,
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		// Check: readiness probe, without which traffic reaches containers
+		// that are still starting or are overloaded
+		if container.ReadinessProbe == nil {
+			rec.Fail(issue("HK-074", "Missing Readiness Probe",
+				fmt.Sprintf("runs container %s without a readiness probe", container.Name),
+				SeverityMedium, "Add a 'readinessProbe' so Services only route to containers ready to serve.", container.Name))
+		} else {
+			rec.Pass("HK-074")
+		}
+
+		// Check: liveness probe, without which a hung container is never restarted
+		if container.LivenessProbe == nil {
+			rec.Fail(issue("HK-075", "Missing Liveness Probe",
+				fmt.Sprintf("runs container %s without a liveness probe", container.Name),
+				SeverityLow, "Add a 'livenessProbe' so the kubelet restarts the container when it stops responding.", container.Name))
+		} else {
+			rec.Pass("HK-075")
+		}
+	}
+}
+
+// checkReplicas reports critical Deployments that run a single replica.
+// A Deployment is critical when a Service routes to it or it has a
+// priority class.
+func (s *ReliabilityScanner) checkReplicas(snap *k8s.Snapshot, rec *Recorder, d *appsv1.Deployment) {
+	services := snap.ServicesMatching(d.Namespace, d.Spec.Template.Labels)
+	priorityClass := d.Spec.Template.Spec.PriorityClassName
+	if len(services) == 0 && priorityClass == "" {
+		return
+	}
+
+	// Check: single replica; a node drain or crash takes the service down
+	if replicas(d.Spec.Replicas) == 1 {
+		var reason string
+		if len(services) > 0 {
+			reason = fmt.Sprintf("backs Service %s", services[0].Name)
+		} else {
+			reason = fmt.Sprintf("has priority class %s", priorityClass)
+		}
+		rec.Fail(Issue{
+			ID:          "HK-076",
+			Title:       "Single-Replica Critical Deployment",
+			Description: fmt.Sprintf("Deployment %s in namespace %s %s but runs a single replica, so any restart or node drain causes an outage", d.Name, d.Namespace, reason),
+			Severity:    SeverityMedium,
+			Resource:    "Deployment/" + d.Name,
+			Namespace:   d.Namespace,
+			Remediation: "Run at least 2 replicas, spread across nodes with topology spread constraints or pod anti-affinity.",
+			Category:    CategoryReliability,
+		})
+	} else {
+		rec.Pass("HK-076")
+	}
+}
+
+// checkDisruptionBudget reports replicated workloads that no disruption
+// budget protects during node drains
+func (s *ReliabilityScanner) checkDisruptionBudget(snap *k8s.Snapshot, rec *Recorder, kind string, meta metav1.ObjectMeta, desired *int32, podLabels map[string]string) {
+	// A budget cannot protect a single replica without blocking drains
+	if replicas(desired) < 2 {
+		return
+	}
+
+	// Check: workloads without a PodDisruptionBudget
+	if len(snap.PodDisruptionBudgetsMatching(meta.Namespace, podLabels)) == 0 {
+		rec.Fail(Issue{
+			ID:          "HK-077",
+			Title:       "Missing PodDisruptionBudget",
+			Description: fmt.Sprintf("%s %s in namespace %s runs %d replicas without a PodDisruptionBudget, so a node drain may evict all of them at once", kind, meta.Name, meta.Namespace, replicas(desired)),
+			Severity:    SeverityLow,
+			Resource:    kind + "/" + meta.Name,
+			Namespace:   meta.Namespace,
+			Remediation: "Create a PodDisruptionBudget for the workload's pods with 'maxUnavailable: 1'.",
+			Category:    CategoryReliability,
+		})
+	} else {
+		rec.Pass("HK-077")
+	}
+}
+
+// checkBudget reports disruption budgets that never allow an eviction
+func (s *ReliabilityScanner) checkBudget(snap *k8s.Snapshot, rec *Recorder, pdb *policyv1.PodDisruptionBudget) {
+	expected := int(pdb.Status.ExpectedPods)
+	if expected == 0 && pdb.Spec.Selector != nil {
+		if selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector); err == nil {
+			for _, pod := range snap.PodsInNamespace(pdb.Namespace) {
+				if selector.Matches(labels.Set(pod.Labels)) {
+					expected++
+				}
+			}
+		}
+	}
+
+	// Check: budgets that block every voluntary eviction, stalling node
+	// drains and cluster upgrades
+	if blocksEvictions(pdb, expected) {
+		rec.Fail(Issue{
+			ID:          "HK-078",
+			Title:       "PodDisruptionBudget Blocks All Evictions",
+			Description: fmt.Sprintf("PodDisruptionBudget %s in namespace %s allows no voluntary disruption of its %d pods, so node drains and upgrades cannot proceed", pdb.Name, pdb.Namespace, expected),
+			Severity:    SeverityMedium,
+			Resource:    "PodDisruptionBudget/" + pdb.Name,
+			Namespace:   pdb.Namespace,
+			Remediation: "Set 'maxUnavailable' to at least 1, or 'minAvailable' below the number of replicas.",
+			Category:    CategoryReliability,
+		})
+	} else {
+		rec.Pass("HK-078")
+	}
+}
+
+// blocksEvictions reports whether a budget can never allow a voluntary
+// eviction of its expected pods. Without a pod count only literal settings
+// such as 'maxUnavailable: 0' or 'minAvailable: 100%' are recognised.
+func blocksEvictions(pdb *policyv1.PodDisruptionBudget, expected int) bool {
+	switch spec := pdb.Spec; {
+	case spec.MaxUnavailable != nil:
+		if expected == 0 {
+			value := spec.MaxUnavailable.String()
+			return value == "0" || value == "0%"
+		}
+		allowed, err := intstr.GetScaledValueFromIntOrPercent(spec.MaxUnavailable, expected, true)
+		return err == nil && allowed == 0
+	case spec.MinAvailable != nil:
+		if expected == 0 {
+			return spec.MinAvailable.String() == "100%"
+		}
+		required, err := intstr.GetScaledValueFromIntOrPercent(spec.MinAvailable, expected, true)
+		return err == nil && required >= expected
+	}
+	return false
+}
+
+// replicas returns a workload's desired replica count, which defaults to 1
+func replicas(desired *int32) int {
+	if desired == nil {
+		return 1
+	}
+	return int(*desired)
+}
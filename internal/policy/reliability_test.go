@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestBlocksEvictions(t *testing.T) {
+	budget := func(minAvailable, maxUnavailable *intstr.IntOrString) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{Spec: policyv1.PodDisruptionBudgetSpec{MinAvailable: minAvailable, MaxUnavailable: maxUnavailable}}
+	}
+	value := func(v intstr.IntOrString) *intstr.IntOrString { return &v }
+
+	tests := []struct {
+		name     string
+		pdb      *policyv1.PodDisruptionBudget
+		expected int
+		want     bool
+	}{
+		{"maxUnavailable 0", budget(nil, value(intstr.FromInt32(0))), 3, true},
+		{"maxUnavailable 1", budget(nil, value(intstr.FromInt32(1))), 3, false},
+		{"maxUnavailable 10% rounds up", budget(nil, value(intstr.FromString("10%"))), 3, false},
+		{"minAvailable equals replicas", budget(value(intstr.FromInt32(3)), nil), 3, true},
+		{"minAvailable below replicas", budget(value(intstr.FromInt32(2)), nil), 3, false},
+		{"minAvailable 100% without pods", budget(value(intstr.FromString("100%")), nil), 0, true},
+		{"minAvailable 50% without pods", budget(value(intstr.FromString("50%")), nil), 0, false},
+	}
+	for _, tt := range tests {
+		if got := blocksEvictions(tt.pdb, tt.expected); got != tt.want {
+			t.Errorf("%s: blocksEvictions = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReliabilityScanner(t *testing.T) {
+	controller := true
+	count := func(n int32) *int32 { return &n }
+	appLabels := map[string]string{"app": "api"}
+	probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"}}}
+
+	pod := func(name string, owner metav1.OwnerReference, labels map[string]string, container corev1.Container) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: labels, OwnerReferences: []metav1.OwnerReference{owner}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+		}
+	}
+	apiOwner := metav1.OwnerReference{Kind: "StatefulSet", Name: "api", UID: "sts1", Controller: &controller}
+	jobOwner := metav1.OwnerReference{Kind: "Job", Name: "migrate", UID: "job1", Controller: &controller}
+
+	snap := &k8s.Snapshot{
+		Pods: []corev1.Pod{
+			pod("api-0", apiOwner, appLabels, corev1.Container{Name: "api", ReadinessProbe: probe}),
+			pod("api-1", apiOwner, appLabels, corev1.Container{Name: "api", ReadinessProbe: probe}),
+			pod("migrate-x", jobOwner, nil, corev1.Container{Name: "migrate"}),
+		},
+		StatefulSets: []appsv1.StatefulSet{{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: count(2),
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: appLabels}},
+			},
+		}},
+		Deployments: []appsv1.Deployment{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: count(3),
+					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "worker"}}},
+				},
+			},
+		},
+		Services: []corev1.Service{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		}},
+		PodDisruptionBudgets: []policyv1.PodDisruptionBudget{{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: func() *intstr.IntOrString { v := intstr.FromString("100%"); return &v }(),
+				Selector:     &metav1.LabelSelector{MatchLabels: appLabels},
+			},
+		}},
+	}
+
+	rec := NewRecorder()
+	if err := (&ReliabilityScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-074": 0, "HK-075": 1, "HK-076": 1, "HK-077": 1, "HK-078": 1})
+
+	for _, issue := range rec.Issues() {
+		switch issue.ID {
+		case "HK-075":
+			if issue.Resource != "StatefulSet/api" {
+				t.Errorf("expected the Job to be exempt from probe checks, got %s", issue.Resource)
+			}
+		case "HK-076":
+			if issue.Resource != "Deployment/web" {
+				t.Errorf("expected the Service-backed Deployment to be reported, got %s", issue.Resource)
+			}
+		case "HK-077":
+			if issue.Resource != "Deployment/worker" {
+				t.Errorf("expected the unprotected Deployment to be reported, got %s", issue.Resource)
+			}
+		}
+	}
+}
+
+func TestReliabilityScannerPriorityClassWithoutService(t *testing.T) {
+	snap := &k8s.Snapshot{
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "shop"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "batch"}},
+					Spec:       corev1.PodSpec{PriorityClassName: "business-critical"},
+				},
+			},
+		}},
+	}
+
+	rec := NewRecorder()
+	if err := (&ReliabilityScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	issues := rec.Issues()
+	assertIssueCounts(t, issues, map[string]int{"HK-076": 1})
+	if len(issues) != 1 || !strings.Contains(issues[0].Description, "priority class business-critical") {
+		t.Errorf("expected the priority class to be given as the reason, got %+v", issues)
+	}
+}
@@ -0,0 +1,175 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// systemNamespaces are managed by Kubernetes itself and are rarely given quotas
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// ResourceScanner audits requests, limits, quotas and LimitRanges, which
+// bound how much a single workload can take from its neighbours
+type ResourceScanner struct{}
+
+// Name returns the scanner identifier
+func (s *ResourceScanner) Name() string {
+	return "resources"
+}
+
+// Resources returns the resources the resource governance scanner reads
+func (s *ResourceScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{
+		k8s.ResourcePods,
+		k8s.ResourceNamespaces,
+		k8s.ResourceResourceQuotas,
+		k8s.ResourceLimitRanges,
+	}, k8s.WorkloadResources...)
+}
+
+// missingResources lists which of the given resources a resource list lacks
+func missingResources(list corev1.ResourceList, names ...corev1.ResourceName) []string {
+	var missing []string
+	for _, name := range names {
+		if _, ok := list[name]; !ok {
+			missing = append(missing, string(name))
+		}
+	}
+	return missing
+}
+
+// Scan runs the resource governance checks
+func (s *ResourceScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	// Replicas of the same workload share their spec; report them once
+	seen := map[string]bool{}
+
+	for i := range snap.Pods {
+		pod := &snap.Pods[i]
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		workload := snap.WorkloadFor(pod)
+		key := fmt.Sprintf("%s/%s/%s", workload.Namespace, workload.Kind, workload.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		s.checkPod(rec, workload, pod)
+	}
+
+	for i := range snap.Namespaces {
+		ns := &snap.Namespaces[i]
+		rec.Scanned("Namespace", "", ns.Name)
+		if systemNamespaces[ns.Name] {
+			continue
+		}
+
+		if len(snap.ResourceQuotasInNamespace(ns.Name)) == 0 {
+			rec.Fail(Issue{
+				ID:          "HK-031",
+				Title:       "Namespace Without ResourceQuota",
+				Description: fmt.Sprintf("Namespace %s has no ResourceQuota, so its workloads can consume unbounded cluster capacity", ns.Name),
+				Severity:    SeverityLow,
+				Resource:    "Namespace/" + ns.Name,
+				Namespace:   ns.Name,
+				Remediation: "Create a ResourceQuota that caps CPU, memory and object counts for the namespace.",
+				Category:    CategoryResourceGovernance,
+			})
+		} else {
+			rec.Pass("HK-031")
+		}
+
+		if len(snap.LimitRangesInNamespace(ns.Name)) == 0 {
+			rec.Fail(Issue{
+				ID:          "HK-032",
+				Title:       "Namespace Without LimitRange",
+				Description: fmt.Sprintf("Namespace %s has no LimitRange, so containers without explicit resources get no defaults", ns.Name),
+				Severity:    SeverityLow,
+				Resource:    "Namespace/" + ns.Name,
+				Namespace:   ns.Name,
+				Remediation: "Create a LimitRange with default requests and limits for containers.",
+				Category:    CategoryResourceGovernance,
+			})
+		} else {
+			rec.Pass("HK-032")
+		}
+	}
+	return nil
+}
+
+func (s *ResourceScanner) checkPod(rec *Recorder, workload k8s.WorkloadRef, pod *corev1.Pod) {
+	resource := workload.Kind + "/" + workload.Name
+	issue := func(id, title, description string, severity Severity, remediation string, container podContainer) Issue {
+		return Issue{
+			ID:            id,
+			Title:         title,
+			Description:   fmt.Sprintf("%s %s in namespace %s %s", workload.Kind, workload.Name, workload.Namespace, description),
+			Severity:      severity,
+			Resource:      resource,
+			Namespace:     workload.Namespace,
+			Remediation:   remediation,
+			Category:      CategoryResourceGovernance,
+			Container:     container.Name,
+			ContainerType: container.Type,
+		}
+	}
+
+	// Disk-backed emptyDir volumes without a size limit fill the node's
+	// ephemeral storage unless the containers are limited
+	var unboundedEmptyDirs []string
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil && volume.EmptyDir.Medium != corev1.StorageMediumMemory && volume.EmptyDir.SizeLimit == nil {
+			unboundedEmptyDirs = append(unboundedEmptyDirs, volume.Name)
+		}
+	}
+
+	for _, container := range podContainers(&pod.Spec) {
+		// Ephemeral containers cannot declare resources
+		if container.Type == ContainerTypeEphemeral {
+			continue
+		}
+		resources := container.Resources
+
+		// Check: requests
+		if missing := missingResources(resources.Requests, corev1.ResourceCPU, corev1.ResourceMemory); len(missing) > 0 {
+			rec.Fail(issue("HK-029", "Missing Resource Requests",
+				fmt.Sprintf("runs container %s without %s requests", container.Name, strings.Join(missing, " and ")),
+				SeverityLow, "Set 'resources.requests' for CPU and memory so the scheduler can place the pod safely.", container))
+		} else {
+			rec.Pass("HK-029")
+		}
+
+		// Check: limits; a missing memory limit is the bigger risk
+		if missing := missingResources(resources.Limits, corev1.ResourceCPU, corev1.ResourceMemory); len(missing) > 0 {
+			severity := SeverityLow
+			if _, ok := resources.Limits[corev1.ResourceMemory]; !ok {
+				severity = SeverityMedium
+			}
+			rec.Fail(issue("HK-030", "Missing Resource Limits",
+				fmt.Sprintf("runs container %s without %s limits", container.Name, strings.Join(missing, " and ")),
+				severity, "Set 'resources.limits' for CPU and memory to cap what the container can consume.", container))
+		} else {
+			rec.Pass("HK-030")
+		}
+
+		// Check: ephemeral storage limit when the pod writes to emptyDir
+		if len(unboundedEmptyDirs) == 0 {
+			continue
+		}
+		if _, ok := resources.Limits[corev1.ResourceEphemeralStorage]; !ok {
+			rec.Fail(issue("HK-033", "Missing Ephemeral Storage Limit",
+				fmt.Sprintf("uses emptyDir volume %s without a size limit and runs container %s without an ephemeral-storage limit", strings.Join(unboundedEmptyDirs, ", "), container.Name),
+				SeverityMedium, "Set 'resources.limits.ephemeral-storage' on the container or 'sizeLimit' on the emptyDir volume.", container))
+		} else {
+			rec.Pass("HK-033")
+		}
+	}
+}
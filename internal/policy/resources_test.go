@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResourceScanner(t *testing.T) {
+	controller := true
+	bounded := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+	replica := func(name string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "shop",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", UID: "sts1", Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "db", Resources: bounded},
+					{Name: "exporter", Resources: corev1.ResourceRequirements{Requests: bounded.Requests}},
+				},
+				Volumes: []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+			},
+		}
+	}
+
+	snap := &k8s.Snapshot{
+		Pods: []corev1.Pod{replica("db-0"), replica("db-1")},
+		Namespaces: []corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "billing"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		},
+		ResourceQuotas: []corev1.ResourceQuota{{ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "shop"}}},
+		LimitRanges:    []corev1.LimitRange{{ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "shop"}}},
+	}
+
+	rec := NewRecorder()
+	if err := (&ResourceScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-029": 0, "HK-030": 1, "HK-031": 1, "HK-032": 1, "HK-033": 2})
+
+	for _, issue := range rec.Issues() {
+		switch issue.ID {
+		case "HK-030", "HK-033":
+			if issue.Resource != "StatefulSet/db" {
+				t.Errorf("expected findings on the owning workload, got %s", issue.Resource)
+			}
+		case "HK-031", "HK-032":
+			if issue.Namespace != "billing" {
+				t.Errorf("expected only namespace billing to be reported, got %s", issue.Namespace)
+			}
+		}
+	}
+}
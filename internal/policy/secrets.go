@@ -0,0 +1,273 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// sensitiveName matches keys and variable names that usually hold credentials
+var sensitiveName = regexp.MustCompile(`(?i)(passw(or)?d|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential)`)
+
+// referenceName matches names that point at a credential instead of holding it
+var referenceName = regexp.MustCompile(`(?i)(_file|_path|_dir|_name|_ref|_url|_endpoint)$`)
+
+// credentialPatterns recognize well-known credential formats regardless of the key name
+var credentialPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"credentials in URL", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:[^/\s:@]+@`)},
+}
+
+// assignmentPattern finds "password = ..." style lines inside configuration files
+var assignmentPattern = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api[_-]?key)["']?\s*[:=]\s*["']?[^\s"'$<{]{6,}`)
+
+// Entropy detection is limited to long single tokens. Hex strings such as
+// digests and UUIDs stay below the threshold.
+const (
+	minEntropyLength = 32
+	minEntropy       = 4.5
+)
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	length := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// isPlaceholder reports whether a value is too trivial to be a credential
+func isPlaceholder(value string) bool {
+	value = strings.TrimSpace(value)
+	if len(value) < 4 || strings.HasPrefix(value, "/") || strings.Contains(value, "$(") || strings.Contains(value, "${") {
+		return true
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "none", "null", "changeme", "redacted":
+		return true
+	}
+	return false
+}
+
+// credentialReason explains why a key/value pair looks like a plaintext
+// credential, or returns an empty string. The value is never part of the reason.
+func credentialReason(name, value string) string {
+	for _, p := range credentialPatterns {
+		if p.pattern.MatchString(value) {
+			return p.name
+		}
+	}
+	if isPlaceholder(value) {
+		return ""
+	}
+	if strings.Contains(value, "\n") {
+		if assignmentPattern.MatchString(value) {
+			return "credential assignment"
+		}
+		return ""
+	}
+	if sensitiveName.MatchString(name) && !referenceName.MatchString(name) {
+		return "credential-like name"
+	}
+	if len(value) >= minEntropyLength && !strings.ContainsAny(value, " \t") && !strings.Contains(value, "://") && shannonEntropy(value) >= minEntropy {
+		return "high-entropy value"
+	}
+	return ""
+}
+
+// SecretsScanner looks for credentials exposed outside of Secrets and for
+// service account tokens handed to pods that do not need them. Long-lived
+// token Secrets are reported by ServiceAccountScanner.
+type SecretsScanner struct{}
+
+// Name returns the scanner identifier
+func (s *SecretsScanner) Name() string {
+	return "secrets"
+}
+
+// Resources returns the resources the secrets scanner reads
+func (s *SecretsScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{
+		k8s.ResourcePods,
+		k8s.ResourceConfigMaps,
+		k8s.ResourceServiceAccounts,
+		k8s.ResourceRoleBindings,
+		k8s.ResourceClusterRoleBindings,
+	}, k8s.WorkloadResources...)
+}
+
+// Scan runs the secrets hygiene checks
+func (s *SecretsScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	// Replicas of the same workload share their spec; report them once
+	seen := map[string]bool{}
+
+	for i := range snap.Pods {
+		pod := &snap.Pods[i]
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		workload := snap.WorkloadFor(pod)
+		key := fmt.Sprintf("%s/%s/%s", workload.Namespace, workload.Kind, workload.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		s.checkEnv(rec, workload, pod)
+		s.checkSecretVolumes(rec, workload, pod)
+		s.checkTokenAutomount(rec, snap, workload, pod)
+	}
+
+	for i := range snap.ConfigMaps {
+		s.checkConfigMap(rec, &snap.ConfigMaps[i])
+	}
+	return nil
+}
+
+func (s *SecretsScanner) checkEnv(rec *Recorder, workload k8s.WorkloadRef, pod *corev1.Pod) {
+	for _, container := range podContainers(&pod.Spec) {
+		var findings []string
+		for _, env := range container.Env {
+			if env.ValueFrom != nil {
+				continue
+			}
+			if reason := credentialReason(env.Name, env.Value); reason != "" {
+				findings = append(findings, fmt.Sprintf("%s (%s)", env.Name, reason))
+			}
+		}
+
+		if len(findings) == 0 {
+			rec.Pass("HK-010")
+			continue
+		}
+		rec.Fail(Issue{
+			ID:    "HK-010",
+			Title: "Plaintext Credential In Environment Variable",
+			Description: fmt.Sprintf("%s %s in namespace %s sets credentials as plain environment values in container %s: %s",
+				workload.Kind, workload.Name, workload.Namespace, container.Name, strings.Join(findings, ", ")),
+			Severity:      SeverityHigh,
+			Resource:      workload.Kind + "/" + workload.Name,
+			Namespace:     workload.Namespace,
+			Remediation:   "Move the values into a Secret and reference them with 'valueFrom.secretKeyRef'.",
+			Category:      CategorySecrets,
+			Container:     container.Name,
+			ContainerType: container.Type,
+		})
+	}
+}
+
+func (s *SecretsScanner) checkConfigMap(rec *Recorder, cm *corev1.ConfigMap) {
+	rec.Scanned("ConfigMap", cm.Namespace, cm.Name)
+
+	var findings []string
+	for key, value := range cm.Data {
+		if reason := credentialReason(key, value); reason != "" {
+			findings = append(findings, fmt.Sprintf("%s (%s)", key, reason))
+		}
+	}
+
+	if len(findings) == 0 {
+		rec.Pass("HK-011")
+		return
+	}
+	sort.Strings(findings)
+	rec.Fail(Issue{
+		ID:    "HK-011",
+		Title: "Plaintext Credential In ConfigMap",
+		Description: fmt.Sprintf("ConfigMap %s in namespace %s appears to store credentials under keys: %s",
+			cm.Name, cm.Namespace, strings.Join(findings, ", ")),
+		Severity:    SeverityHigh,
+		Resource:    "ConfigMap/" + cm.Name,
+		Namespace:   cm.Namespace,
+		Remediation: "Move the credentials into a Secret and rotate them, since ConfigMaps are readable by more identities.",
+		Category:    CategorySecrets,
+	})
+}
+
+// checkSecretVolumes reports Secret volumes that no container mounts. The
+// kubelet still fetches them onto the node, exposing them for no benefit.
+func (s *SecretsScanner) checkSecretVolumes(rec *Recorder, workload k8s.WorkloadRef, pod *corev1.Pod) {
+	mounted := map[string]bool{}
+	for _, container := range podContainers(&pod.Spec) {
+		for _, mount := range container.VolumeMounts {
+			mounted[mount.Name] = true
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		var secrets []string
+		if volume.Secret != nil {
+			secrets = append(secrets, volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					secrets = append(secrets, source.Secret.Name)
+				}
+			}
+		}
+		if len(secrets) == 0 {
+			continue
+		}
+
+		if mounted[volume.Name] {
+			rec.Pass("HK-012")
+			continue
+		}
+		rec.Fail(Issue{
+			ID:    "HK-012",
+			Title: "Unused Secret Volume",
+			Description: fmt.Sprintf("%s %s in namespace %s declares volume %s with Secret %s, but no container mounts it",
+				workload.Kind, workload.Name, workload.Namespace, volume.Name, strings.Join(secrets, ", ")),
+			Severity:    SeverityLow,
+			Resource:    workload.Kind + "/" + workload.Name,
+			Namespace:   workload.Namespace,
+			Remediation: "Remove the unused volume so the Secret is not delivered to the node.",
+			Category:    CategorySecrets,
+		})
+	}
+}
+
+// checkTokenAutomount reports pods that receive a service account token
+// although the account has no RBAC permissions, so the pod has no reason to
+// call the API server
+func (s *SecretsScanner) checkTokenAutomount(rec *Recorder, snap *k8s.Snapshot, workload k8s.WorkloadRef, pod *corev1.Pod) {
+	account, automount := serviceAccountToken(snap, pod)
+	if !automount || snap.ServiceAccountBound(pod.Namespace, account) {
+		rec.Pass("HK-014")
+		return
+	}
+	rec.Fail(Issue{
+		ID:    "HK-014",
+		Title: "Unneeded Service Account Token",
+		Description: fmt.Sprintf("%s %s in namespace %s automounts a token for service account %s, which has no RBAC bindings",
+			workload.Kind, workload.Name, workload.Namespace, account),
+		Severity:    SeverityMedium,
+		Resource:    workload.Kind + "/" + workload.Name,
+		Namespace:   workload.Namespace,
+		Remediation: "Set 'automountServiceAccountToken: false' on the pod or its service account.",
+		Category:    CategorySecrets,
+	})
+}
@@ -0,0 +1,270 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// defaultServiceAccount is the account pods run as when none is set
+const defaultServiceAccount = "default"
+
+// serviceAccountToken returns the service account a pod runs as and whether
+// its token is mounted, applying the pod setting over the account setting
+func serviceAccountToken(snap *k8s.Snapshot, pod *corev1.Pod) (string, bool) {
+	account := pod.Spec.ServiceAccountName
+	if account == "" {
+		account = defaultServiceAccount
+	}
+
+	automount := true
+	if pod.Spec.AutomountServiceAccountToken != nil {
+		automount = *pod.Spec.AutomountServiceAccountToken
+	} else if sa := snap.ServiceAccount(pod.Namespace, account); sa != nil && sa.AutomountServiceAccountToken != nil {
+		automount = *sa.AutomountServiceAccountToken
+	}
+	return account, automount
+}
+
+// powerfulRule explains why a policy rule grants dangerous access, or returns
+// an empty string
+func powerfulRule(rule rbacv1.PolicyRule) string {
+	has := func(list []string, values ...string) bool {
+		for _, item := range list {
+			if item == rbacv1.VerbAll {
+				return true
+			}
+			for _, value := range values {
+				if item == value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has(rule.Verbs, "escalate", "bind", "impersonate"):
+		return "escalate, bind or impersonate"
+	case has(rule.Resources, "secrets") && has(rule.Verbs, "get", "list", "watch"):
+		return "read access to secrets"
+	case has(rule.Resources, "pods/exec", "pods/attach") && has(rule.Verbs, "create", "get"):
+		return "exec into pods"
+	case has(rule.Resources, "nodes/proxy") && has(rule.Verbs, "get", "create"):
+		return "access to the kubelet API"
+	case has(rule.Resources, "pods", "deployments", "daemonsets", "statefulsets", "jobs", "cronjobs") && has(rule.Verbs, "create", "update", "patch"):
+		return "create or modify workloads"
+	case has(rule.Resources, "*") && has(rule.Verbs, "*"):
+		return "full access"
+	}
+	return ""
+}
+
+// powerfulGrants describes the dangerous permissions held by a service account
+func powerfulGrants(snap *k8s.Snapshot, namespace, account string) []string {
+	var reasons []string
+	for _, grant := range snap.ServiceAccountGrants(namespace, account) {
+		for _, rule := range snap.RulesFor(grant) {
+			if reason := powerfulRule(rule); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("%s %s via %s (%s)", grant.RoleRef.Kind, grant.RoleRef.Name, grant.Binding, reason))
+				break
+			}
+		}
+	}
+	return reasons
+}
+
+// ServiceAccountScanner correlates pods, service accounts and RBAC bindings
+// to find accounts that carry more risk than their workloads need
+type ServiceAccountScanner struct{}
+
+// Name returns the scanner identifier
+func (s *ServiceAccountScanner) Name() string {
+	return "serviceaccounts"
+}
+
+// Resources returns the resources the service account scanner needs
+func (s *ServiceAccountScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{
+		k8s.ResourcePods,
+		k8s.ResourceServices,
+		k8s.ResourceServiceAccounts,
+		k8s.ResourceRoleBindings,
+		k8s.ResourceClusterRoleBindings,
+		k8s.ResourceRoles,
+		k8s.ResourceClusterRoles,
+	}, k8s.WorkloadResources...)
+}
+
+// OptionalResources returns the resources the scanner reads when collected.
+// Without Ingresses only Services count as exposure, and without Secrets
+// the long-lived token check is skipped.
+func (s *ServiceAccountScanner) OptionalResources() []k8s.Resource {
+	return []k8s.Resource{k8s.ResourceIngresses, k8s.ResourceSecrets}
+}
+
+// Scan runs the service account checks
+func (s *ServiceAccountScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	// Service accounts referenced by pods or by workload templates that are
+	// currently scaled to zero
+	used := map[string]bool{}
+	for _, spec := range templateSpecs(snap) {
+		used[spec.namespace+"/"+spec.account] = true
+	}
+
+	seen := map[string]bool{}
+	for i := range snap.Pods {
+		pod := &snap.Pods[i]
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		account, automount := serviceAccountToken(snap, pod)
+		used[pod.Namespace+"/"+account] = true
+
+		workload := snap.WorkloadFor(pod)
+		key := fmt.Sprintf("%s/%s/%s", workload.Namespace, workload.Kind, workload.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		resource := workload.Kind + "/" + workload.Name
+
+		// Check: default service account
+		if account == defaultServiceAccount {
+			rec.Fail(Issue{
+				ID:          "HK-017",
+				Title:       "Workload Uses Default ServiceAccount",
+				Description: fmt.Sprintf("%s %s in namespace %s runs as the namespace 'default' service account, so any permission granted to it is shared by every such workload", workload.Kind, workload.Name, workload.Namespace),
+				Severity:    SeverityLow,
+				Resource:    resource,
+				Namespace:   workload.Namespace,
+				Remediation: "Create a dedicated service account for the workload and set 'serviceAccountName'.",
+				Category:    CategoryServiceAccounts,
+			})
+		} else {
+			rec.Pass("HK-017")
+		}
+
+		// Check: powerful account mounted into an internet-facing pod
+		level, via := podExposure(snap, pod)
+		if level == exposureInternal || !automount {
+			continue
+		}
+		if reasons := powerfulGrants(snap, pod.Namespace, account); len(reasons) > 0 {
+			rec.Fail(Issue{
+				ID:    "HK-015",
+				Title: "Powerful ServiceAccount In Internet-Facing Pod",
+				Description: fmt.Sprintf("%s %s in namespace %s is exposed through %s and mounts the token of service account %s, which is granted %s",
+					workload.Kind, workload.Name, workload.Namespace, strings.Join(via, ", "), account, strings.Join(reasons, "; ")),
+				Severity:    SeverityCritical,
+				Resource:    resource,
+				Namespace:   workload.Namespace,
+				Remediation: "Reduce the account's permissions or disable token automounting for the exposed workload.",
+				Category:    CategoryServiceAccounts,
+			})
+		} else {
+			rec.Pass("HK-015")
+		}
+	}
+
+	for i := range snap.ServiceAccounts {
+		sa := &snap.ServiceAccounts[i]
+		rec.Scanned("ServiceAccount", sa.Namespace, sa.Name)
+
+		// The default account always exists, and kube-system accounts are
+		// used by control plane components that do not run as pods
+		if sa.Name == defaultServiceAccount || sa.Namespace == "kube-system" {
+			continue
+		}
+		if used[sa.Namespace+"/"+sa.Name] {
+			rec.Pass("HK-016")
+			continue
+		}
+
+		description := fmt.Sprintf("ServiceAccount %s in namespace %s is not used by any pod or workload", sa.Name, sa.Namespace)
+		if snap.ServiceAccountBound(sa.Namespace, sa.Name) {
+			description += ", but still holds RBAC permissions"
+		}
+		rec.Fail(Issue{
+			ID:          "HK-016",
+			Title:       "Unused ServiceAccount",
+			Description: description,
+			Severity:    SeverityLow,
+			Resource:    "ServiceAccount/" + sa.Name,
+			Namespace:   sa.Namespace,
+			Remediation: "Delete the service account and its bindings if nothing outside the cluster uses it.",
+			Category:    CategoryServiceAccounts,
+		})
+	}
+
+	for i := range snap.Secrets {
+		secret := &snap.Secrets[i]
+		rec.Scanned("Secret", secret.Namespace, secret.Name)
+
+		if secret.Type != corev1.SecretTypeServiceAccountToken {
+			rec.Pass("HK-013")
+			continue
+		}
+
+		account := secret.Annotations[corev1.ServiceAccountNameKey]
+		description := fmt.Sprintf("Secret %s in namespace %s holds a long-lived token for service account %s", secret.Name, secret.Namespace, account)
+		if snap.ServiceAccount(secret.Namespace, account) == nil {
+			description += ", which no longer exists"
+		} else if reasons := powerfulGrants(snap, secret.Namespace, account); len(reasons) > 0 {
+			description += " with " + strings.Join(reasons, "; ")
+		}
+		rec.Fail(Issue{
+			ID:          "HK-013",
+			Title:       "Legacy Service Account Token Secret",
+			Description: description,
+			Severity:    SeverityMedium,
+			Resource:    "Secret/" + secret.Name,
+			Namespace:   secret.Namespace,
+			Remediation: "Delete the Secret and use short-lived projected tokens or 'kubectl create token' instead.",
+			Category:    CategoryServiceAccounts,
+		})
+	}
+	return nil
+}
+
+// templateSpec is the service account a workload template runs as
+type templateSpec struct {
+	namespace string
+	account   string
+}
+
+// templateSpecs lists the service accounts referenced by workload templates
+func templateSpecs(snap *k8s.Snapshot) []templateSpec {
+	var specs []templateSpec
+	add := func(namespace string, spec corev1.PodSpec) {
+		account := spec.ServiceAccountName
+		if account == "" {
+			account = defaultServiceAccount
+		}
+		specs = append(specs, templateSpec{namespace: namespace, account: account})
+	}
+
+	for _, d := range snap.Deployments {
+		add(d.Namespace, d.Spec.Template.Spec)
+	}
+	for _, rs := range snap.ReplicaSets {
+		add(rs.Namespace, rs.Spec.Template.Spec)
+	}
+	for _, sts := range snap.StatefulSets {
+		add(sts.Namespace, sts.Spec.Template.Spec)
+	}
+	for _, ds := range snap.DaemonSets {
+		add(ds.Namespace, ds.Spec.Template.Spec)
+	}
+	for _, job := range snap.Jobs {
+		add(job.Namespace, job.Spec.Template.Spec)
+	}
+	for _, cj := range snap.CronJobs {
+		add(cj.Namespace, cj.Spec.JobTemplate.Spec.Template.Spec)
+	}
+	return specs
+}
@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceAccountScanner(t *testing.T) {
+	pod := func(name, account string, labels map[string]string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: labels},
+			Spec:       corev1.PodSpec{ServiceAccountName: account},
+		}
+	}
+
+	snap := &k8s.Snapshot{
+		Pods: []corev1.Pod{
+			pod("frontend", "frontend", map[string]string{"app": "frontend"}),
+			pod("backend", "backend", map[string]string{"app": "backend"}),
+			pod("batch", "", nil),
+		},
+		Services: []corev1.Service{
+			{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "shop"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer, Selector: map[string]string{"app": "frontend"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "shop"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Selector: map[string]string{"app": "backend"}}},
+		},
+		ServiceAccounts: []corev1.ServiceAccount{
+			{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "shop"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "shop"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "shop"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "ci-deployer", Namespace: "shop"}},
+		},
+		Secrets: []corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci-deployer-token", Namespace: "shop", Annotations: map[string]string{corev1.ServiceAccountNameKey: "ci-deployer"}},
+			Type:       corev1.SecretTypeServiceAccountToken,
+		}},
+		ClusterRoles: []rbacv1.ClusterRole{{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-reader"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}},
+		}},
+		ClusterRoleBindings: []rbacv1.ClusterRoleBinding{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-secrets"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "secret-reader"},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: "frontend", Namespace: "shop"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "backend", Namespace: "shop"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "ci-deployer", Namespace: "shop"},
+			},
+		}},
+	}
+
+	rec := NewRecorder()
+	if err := (&ServiceAccountScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-013": 1, "HK-015": 1, "HK-016": 1, "HK-017": 1})
+
+	for _, issue := range rec.Issues() {
+		switch issue.ID {
+		case "HK-015":
+			if issue.Resource != "Pod/frontend" || !strings.Contains(issue.Description, "read access to secrets") {
+				t.Errorf("unexpected HK-015 issue: %+v", issue)
+			}
+		case "HK-016":
+			if issue.Resource != "ServiceAccount/ci-deployer" || !strings.Contains(issue.Description, "RBAC permissions") {
+				t.Errorf("unexpected HK-016 issue: %+v", issue)
+			}
+		case "HK-017":
+			if issue.Resource != "Pod/batch" {
+				t.Errorf("unexpected HK-017 issue: %+v", issue)
+			}
+		}
+	}
+}
+
+func TestServiceAccountScannerRunsWithoutOptionalResources(t *testing.T) {
+	engine := &Engine{
+		workers:  1,
+		scanners: []Scanner{&ServiceAccountScanner{}},
+		excluded: map[k8s.Resource]bool{k8s.ResourceSecrets: true},
+	}
+
+	snap := &k8s.Snapshot{
+		Pods: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "shop"}}},
+	}
+	for _, r := range k8s.AllResources {
+		if r != k8s.ResourceIngresses && r != k8s.ResourceSecrets {
+			snap.Collected = append(snap.Collected, r.String())
+		}
+	}
+	snap.Errors = []k8s.CollectError{{Resource: k8s.ResourceIngresses.String(), Reason: k8s.ReasonForbidden, Message: "forbidden"}}
+
+	result, err := engine.Evaluate(context.Background(), snap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIssueCounts(t, result.Issues, map[string]int{"HK-017": 1})
+
+	// The skipped checks are still visible in the result
+	reasons := map[string]string{}
+	for _, scanErr := range result.Errors {
+		reasons[scanErr.Resource] = scanErr.Reason
+	}
+	want := map[string]string{
+		k8s.ResourceIngresses.String(): k8s.ReasonForbidden,
+		k8s.ResourceSecrets.String():   k8s.ReasonExcluded,
+	}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("expected gaps %v, got %+v", want, result.Errors)
+	}
+}
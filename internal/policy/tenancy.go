@@ -0,0 +1,273 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// DefaultRequiredNamespaceLabels are the labels every namespace must carry
+// when the tenancy model does not list its own
+var DefaultRequiredNamespaceLabels = []string{"owner", "team", podSecurityEnforceLabel}
+
+// Pod Security Admission reads the levels of a namespace from these labels
+const (
+	podSecurityLabelPrefix  = "pod-security.kubernetes.io/"
+	podSecurityEnforceLabel = podSecurityLabelPrefix + "enforce"
+)
+
+// TenancyModel describes which tenant owns each namespace and what every
+// namespace must declare. It is read from the 'tenancy' config section.
+type TenancyModel struct {
+	// RequiredLabels must be set on every namespace outside the system
+	// namespaces; nil uses DefaultRequiredNamespaceLabels
+	RequiredLabels []string `mapstructure:"required-labels"`
+	// TenantLabel is a namespace label whose value names the owning tenant
+	TenantLabel string `mapstructure:"tenant-label"`
+	// Tenants lists tenants by name; they take precedence over TenantLabel
+	Tenants map[string]Tenant `mapstructure:"tenants"`
+}
+
+// Tenant is a team or customer sharing the cluster
+type Tenant struct {
+	// Namespaces owned by the tenant; a pattern may use shell globs such as payments-*
+	Namespaces []string `mapstructure:"namespaces"`
+	// Users and Groups are the RBAC subjects belonging to the tenant
+	Users  []string `mapstructure:"users"`
+	Groups []string `mapstructure:"groups"`
+}
+
+// Validate checks the namespace patterns of every tenant, so a typo fails
+// the scan instead of silently matching nothing
+func (m TenancyModel) Validate() error {
+	for _, tenant := range sortedTenants(m.Tenants) {
+		for _, pattern := range m.Tenants[tenant].Namespaces {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("tenant %s: invalid namespace pattern %q: %w", tenant, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// namespaceTenant returns the tenant that owns a namespace, or an empty
+// string. Patterns are expected to have passed Validate.
+func (m TenancyModel) namespaceTenant(ns *corev1.Namespace, name string) string {
+	for _, tenant := range sortedTenants(m.Tenants) {
+		for _, pattern := range m.Tenants[tenant].Namespaces {
+			if ok, _ := path.Match(pattern, name); ok {
+				return tenant
+			}
+		}
+	}
+	if m.TenantLabel != "" && ns != nil {
+		return ns.Labels[m.TenantLabel]
+	}
+	return ""
+}
+
+// subjectTenant returns the tenant an RBAC subject belongs to, or an empty
+// string when the subject is not attributed to any tenant
+func (m TenancyModel) subjectTenant(snap *k8s.Snapshot, subject rbacv1.Subject, bindingNamespace string) string {
+	// Service accounts belong to the tenant owning their namespace
+	saNamespace := ""
+	switch subject.Kind {
+	case rbacv1.ServiceAccountKind:
+		saNamespace = subject.Namespace
+		if saNamespace == "" {
+			saNamespace = bindingNamespace
+		}
+	case rbacv1.UserKind:
+		if rest, ok := strings.CutPrefix(subject.Name, "system:serviceaccount:"); ok {
+			saNamespace, _, _ = strings.Cut(rest, ":")
+		}
+	case rbacv1.GroupKind:
+		if rest, ok := strings.CutPrefix(subject.Name, "system:serviceaccounts:"); ok {
+			saNamespace = rest
+		}
+	}
+	if saNamespace != "" {
+		return m.namespaceTenant(snap.NamespaceByName(saNamespace), saNamespace)
+	}
+
+	for _, tenant := range sortedTenants(m.Tenants) {
+		members := m.Tenants[tenant].Users
+		if subject.Kind == rbacv1.GroupKind {
+			members = m.Tenants[tenant].Groups
+		}
+		if slices.Contains(members, subject.Name) {
+			return tenant
+		}
+	}
+	return ""
+}
+
+func sortedTenants(tenants map[string]Tenant) []string {
+	names := make([]string, 0, len(tenants))
+	for name := range tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TenancyScanner audits namespace hygiene and the isolation between tenants
+// sharing the cluster
+type TenancyScanner struct {
+	Model TenancyModel
+}
+
+// Name returns the scanner identifier
+func (s *TenancyScanner) Name() string {
+	return "tenancy"
+}
+
+// Resources returns the resources the tenancy scanner reads
+func (s *TenancyScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{
+		k8s.ResourcePods,
+		k8s.ResourceNamespaces,
+		k8s.ResourceNetworkPolicies,
+		k8s.ResourceResourceQuotas,
+		k8s.ResourceLimitRanges,
+		k8s.ResourceRoleBindings,
+	}, k8s.WorkloadResources...)
+}
+
+// Scan runs the namespace and tenancy checks
+func (s *TenancyScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	s.checkDefaultNamespace(snap, rec)
+	for i := range snap.Namespaces {
+		s.checkNamespace(snap, rec, &snap.Namespaces[i])
+	}
+	for i := range snap.RoleBindings {
+		s.checkRoleBinding(snap, rec, &snap.RoleBindings[i])
+	}
+	return nil
+}
+
+// checkDefaultNamespace reports workloads deployed to the default namespace
+func (s *TenancyScanner) checkDefaultNamespace(snap *k8s.Snapshot, rec *Recorder) {
+	seen := map[string]bool{}
+	for i := range snap.Pods {
+		pod := &snap.Pods[i]
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		workload := snap.WorkloadFor(pod)
+		key := workload.Kind + "/" + workload.Name
+		if seen[workload.Namespace+"/"+key] {
+			continue
+		}
+		seen[workload.Namespace+"/"+key] = true
+
+		// Check: workloads in the default namespace
+		if pod.Namespace == corev1.NamespaceDefault {
+			rec.Fail(Issue{
+				ID:          "HK-070",
+				Title:       "Workload In Default Namespace",
+				Description: fmt.Sprintf("%s %s runs in the default namespace, which has no owner and is where unscoped RBAC grants and kubectl commands land", workload.Kind, workload.Name),
+				Severity:    SeverityMedium,
+				Resource:    key,
+				Namespace:   workload.Namespace,
+				Remediation: "Move the workload to a dedicated namespace owned by its team.",
+				Category:    CategoryTenancy,
+			})
+		} else {
+			rec.Pass("HK-070")
+		}
+	}
+}
+
+func (s *TenancyScanner) checkNamespace(snap *k8s.Snapshot, rec *Recorder, ns *corev1.Namespace) {
+	rec.Scanned("Namespace", "", ns.Name)
+	if systemNamespaces[ns.Name] {
+		return
+	}
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("Namespace %s %s", ns.Name, description),
+			Severity:    severity,
+			Resource:    "Namespace/" + ns.Name,
+			Namespace:   ns.Name,
+			Remediation: remediation,
+			Category:    CategoryTenancy,
+		}
+	}
+
+	// Check: labels the tenancy model requires; a missing Pod Security
+	// label leaves the namespace on the cluster's default admission level
+	required := s.Model.RequiredLabels
+	if required == nil {
+		required = DefaultRequiredNamespaceLabels
+	}
+	var missing []string
+	severity := SeverityLow
+	for _, label := range required {
+		if _, ok := ns.Labels[label]; !ok {
+			missing = append(missing, label)
+			if strings.HasPrefix(label, podSecurityLabelPrefix) {
+				severity = SeverityMedium
+			}
+		}
+	}
+	if len(missing) > 0 {
+		rec.Fail(issue("HK-071", "Namespace Missing Required Labels",
+			fmt.Sprintf("is missing required labels: %s", strings.Join(missing, ", ")),
+			severity, fmt.Sprintf("Label the namespace with %s.", strings.Join(missing, ", "))))
+	} else {
+		rec.Pass("HK-071")
+	}
+
+	// Check: namespaces with no network, quota or limit boundary at all
+	if len(snap.NetworkPoliciesInNamespace(ns.Name)) == 0 &&
+		len(snap.ResourceQuotasInNamespace(ns.Name)) == 0 &&
+		len(snap.LimitRangesInNamespace(ns.Name)) == 0 {
+		rec.Fail(issue("HK-072", "Namespace Without Isolation",
+			"has no NetworkPolicy, ResourceQuota or LimitRange, so nothing separates its workloads from other tenants",
+			SeverityMedium, "Add a default-deny NetworkPolicy, a ResourceQuota and a LimitRange, for example from a namespace template."))
+	} else {
+		rec.Pass("HK-072")
+	}
+}
+
+// checkRoleBinding reports bindings that give another tenant's subjects
+// access to a tenant namespace
+func (s *TenancyScanner) checkRoleBinding(snap *k8s.Snapshot, rec *Recorder, rb *rbacv1.RoleBinding) {
+	owner := s.Model.namespaceTenant(snap.NamespaceByName(rb.Namespace), rb.Namespace)
+	if owner == "" {
+		return
+	}
+	rec.Scanned("RoleBinding", rb.Namespace, rb.Name)
+
+	crossTenant := false
+	for _, subject := range rb.Subjects {
+		tenant := s.Model.subjectTenant(snap, subject, rb.Namespace)
+		if tenant == "" || tenant == owner {
+			continue
+		}
+		// Check: subjects of one tenant bound in another tenant's namespace
+		crossTenant = true
+		rec.Fail(Issue{
+			ID:          "HK-073",
+			Title:       "Cross-Tenant RoleBinding",
+			Description: fmt.Sprintf("RoleBinding %s in namespace %s of tenant %s grants %s to %s %s of tenant %s", rb.Name, rb.Namespace, owner, rb.RoleRef.Name, subject.Kind, subject.Name, tenant),
+			Severity:    SeverityHigh,
+			Resource:    "RoleBinding/" + rb.Name,
+			Namespace:   rb.Namespace,
+			Remediation: "Remove the subject from the binding, or move what both tenants need into a shared namespace.",
+			Category:    CategoryTenancy,
+		})
+	}
+	if !crossTenant {
+		rec.Pass("HK-073")
+	}
+}
@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTenancyScanner(t *testing.T) {
+	namespace := func(name string, labels map[string]string) corev1.Namespace {
+		return corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	labeled := map[string]string{"owner": "alice", "team": "payments", podSecurityEnforceLabel: "restricted"}
+
+	snap := &k8s.Snapshot{
+		Namespaces: []corev1.Namespace{
+			namespace("payments", labeled),
+			namespace("payments-staging", labeled),
+			namespace("search", map[string]string{"owner": "bob", "tenant": "search"}),
+			namespace("kube-system", nil),
+		},
+		Pods: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "payments"}},
+		},
+		NetworkPolicies: []networkingv1.NetworkPolicy{
+			{ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "payments"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "payments-staging"}},
+		},
+		RoleBindings: []rbacv1.RoleBinding{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "ci", Namespace: "payments"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+				Subjects: []rbacv1.Subject{
+					{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "payments-staging"},
+					{Kind: rbacv1.ServiceAccountKind, Name: "indexer", Namespace: "search"},
+					{Kind: rbacv1.GroupKind, Name: "search-oncall"},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "readers", Namespace: "search"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:search"}},
+			},
+		},
+	}
+	model := TenancyModel{
+		TenantLabel: "tenant",
+		Tenants: map[string]Tenant{
+			"payments": {Namespaces: []string{"payments", "payments-*"}},
+			"search":   {Groups: []string{"search-oncall"}},
+		},
+	}
+
+	rec := NewRecorder()
+	if err := (&TenancyScanner{Model: model}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-070": 1, "HK-071": 1, "HK-072": 1, "HK-073": 2})
+	if stats := rec.rules["HK-070"]; stats == nil || stats.Passed != 1 || stats.Failed != 1 {
+		t.Errorf("expected 1 passed and 1 failed default namespace check, got %+v", stats)
+	}
+
+	for _, issue := range rec.Issues() {
+		switch issue.ID {
+		case "HK-071":
+			if issue.Resource != "Namespace/search" || issue.Severity != SeverityMedium {
+				t.Errorf("expected namespace search to miss labels including Pod Security, got %s (%s)", issue.Resource, issue.Severity)
+			}
+		case "HK-073":
+			if issue.Resource != "RoleBinding/ci" {
+				t.Errorf("expected only the payments binding to cross tenants, got %s", issue.Resource)
+			}
+		}
+	}
+}
+
+func TestTenancyModelValidate(t *testing.T) {
+	valid := TenancyModel{Tenants: map[string]Tenant{"payments": {Namespaces: []string{"payments", "payments-*"}}}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := TenancyModel{Tenants: map[string]Tenant{"payments": {Namespaces: []string{"payments-[a-"}}}}
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "payments-[a-") {
+		t.Errorf("expected the bad pattern to be reported, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package policy
 
 import (
 	"context"
+	"time"
 
 	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
 )
@@ -17,6 +18,25 @@ const (
 	SeverityInfo     Severity = "INFO"
 )
 
+// Categories group related rules in reports
+const (
+	CategoryPodSecurity        = "Pod Security"
+	CategoryImageSecurity      = "Image Security"
+	CategoryVulnerability      = "Vulnerabilities"
+	CategorySecrets            = "Secrets"
+	CategoryServiceAccounts    = "Service Accounts"
+	CategoryResourceGovernance = "Resource Governance"
+	CategoryExposure           = "Exposure"
+	CategoryGatewayAPI         = "Gateway API"
+	CategoryAdmissionControl   = "Admission Control"
+	CategoryNodes              = "Nodes"
+	CategoryControlPlane       = "Control Plane"
+	CategoryKubernetesVersion  = "Kubernetes Version"
+	CategoryDeprecatedAPIs     = "Deprecated APIs"
+	CategoryTenancy            = "Multi-Tenancy"
+	CategoryReliability        = "Reliability"
+)
+
 // Issue represents a security finding
 type Issue struct {
 	ID          string   `json:"id" yaml:"id"`
@@ -25,14 +45,43 @@ type Issue struct {
 	Severity    Severity `json:"severity" yaml:"severity"`
 	Resource    string   `json:"resource" yaml:"resource"`
 	Namespace   string   `json:"namespace" yaml:"namespace"`
-	Remediation string   `json:"remediation" yaml:"remediation"`
-	Category    string   `json:"category" yaml:"category"`
+	Container   string   `json:"container,omitempty" yaml:"container,omitempty"`
+	// ContainerType is set with Container and tells init, sidecar, ephemeral and regular containers apart
+	ContainerType ContainerType `json:"container_type,omitempty" yaml:"container_type,omitempty"`
+	Remediation   string        `json:"remediation" yaml:"remediation"`
+	Category      string        `json:"category" yaml:"category"`
+	Cluster       string        `json:"cluster,omitempty" yaml:"cluster,omitempty"`
 }
 
 // Result contains the outcome of a scan
 type Result struct {
+	// Cluster names the scanned cluster, usually the kubeconfig context
+	Cluster string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	// Node is set on node audit results and names the audited node
+	Node   string  `json:"node,omitempty" yaml:"node,omitempty"`
 	Issues []Issue `json:"issues" yaml:"issues"`
 	Stats  Stats   `json:"stats" yaml:"stats"`
+	// Errors lists scanners that could not fully inspect the cluster
+	Errors []ScanError `json:"errors" yaml:"errors"`
+	// Fleet is set on results merged from several clusters
+	Fleet *FleetSummary `json:"fleet,omitempty" yaml:"fleet,omitempty"`
+}
+
+// Partial reports whether any scanner failed, meaning coverage is incomplete
+func (r *Result) Partial() bool {
+	return len(r.Errors) > 0
+}
+
+// ScanError records a scanner whose coverage is missing or incomplete
+type ScanError struct {
+	Cluster string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Scanner string `json:"scanner" yaml:"scanner"`
+	// Reason is one of the k8s.Reason* values, e.g. Forbidden or Timeout
+	Reason   string `json:"reason" yaml:"reason"`
+	Resource string `json:"resource,omitempty" yaml:"resource,omitempty"`
+	// Namespaces lists the affected namespaces; "*" means all namespaces
+	Namespaces []string `json:"namespaces" yaml:"namespaces"`
+	Message    string   `json:"message" yaml:"message"`
 }
 
 // Stats holds summary statistics of the scan
@@ -40,9 +89,47 @@ type Stats struct {
 	TotalIssues      int              `json:"total_issues" yaml:"total_issues"`
 	SeverityCount    map[Severity]int `json:"severity_count" yaml:"severity_count"`
 	ResourcesScanned int              `json:"resources_scanned" yaml:"resources_scanned"`
+	// ResourcesByKind and ResourcesByNamespace break ResourcesScanned down
+	ResourcesByKind      map[string]int `json:"resources_by_kind" yaml:"resources_by_kind"`
+	ResourcesByNamespace map[string]int `json:"resources_by_namespace" yaml:"resources_by_namespace"`
+	ChecksPassed         int            `json:"checks_passed" yaml:"checks_passed"`
+	ChecksFailed         int            `json:"checks_failed" yaml:"checks_failed"`
+	Rules                []RuleStats    `json:"rules" yaml:"rules"`
+	Scanners             []ScannerStats `json:"scanners" yaml:"scanners"`
 }
 
-// Scanner defines the interface for resource-specific scanners
+// RuleStats records how often a rule was evaluated and its outcomes
+type RuleStats struct {
+	ID        string `json:"id" yaml:"id"`
+	Evaluated int    `json:"evaluated" yaml:"evaluated"`
+	Passed    int    `json:"passed" yaml:"passed"`
+	Failed    int    `json:"failed" yaml:"failed"`
+}
+
+// ScannerStats records how a single scanner performed during a scan
+type ScannerStats struct {
+	Name     string        `json:"name" yaml:"name"`
+	Duration time.Duration `json:"duration_ns" yaml:"duration_ns"`
+	Issues   int           `json:"issues" yaml:"issues"`
+	Objects  int           `json:"objects" yaml:"objects"`
+	Checks   int           `json:"checks" yaml:"checks"`
+}
+
+// Scanner defines the interface for resource-specific scanners.
+// Scanners read from a shared snapshot instead of calling the API themselves,
+// so each resource type is listed once per scan.
 type Scanner interface {
-	Scan(ctx context.Context, client *k8s.Client, namespace string) ([]Issue, error)
+	Name() string
+	// Resources lists the API resources the scanner reads from the snapshot
+	Resources() []k8s.Resource
+	// Scan evaluates the snapshot, reporting every inspected object and
+	// every check outcome to rec
+	Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error
+}
+
+// OptionalResourceScanner is implemented by scanners that still run when
+// some of their input is missing, skipping only the checks that need it
+type OptionalResourceScanner interface {
+	// OptionalResources lists resources the scanner reads when they were collected
+	OptionalResources() []k8s.Resource
 }
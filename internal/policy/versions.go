@@ -0,0 +1,212 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// kubernetesEOL lists when each Kubernetes minor release stopped receiving
+// patches upstream. Releases older than the first entry are long past end
+// of life; releases newer than the last entry are treated as supported.
+var kubernetesEOL = []struct {
+	Minor string
+	Date  string
+}{
+	{"1.24", "2023-07-28"},
+	{"1.25", "2023-10-27"},
+	{"1.26", "2024-02-28"},
+	{"1.27", "2024-06-28"},
+	{"1.28", "2024-10-28"},
+	{"1.29", "2025-02-28"},
+	{"1.30", "2025-06-28"},
+	{"1.31", "2025-10-28"},
+	{"1.32", "2026-02-28"},
+	{"1.33", "2026-06-28"},
+	{"1.34", "2026-10-27"},
+	{"1.35", "2027-02-28"},
+}
+
+// kubernetesCVE is a Kubernetes vulnerability and the first patch release
+// of each maintained minor version that fixed it
+type kubernetesCVE struct {
+	ID       string
+	Summary  string
+	Severity Severity
+	Fixed    []string
+	// Windows marks vulnerabilities that only affect Windows nodes
+	Windows bool
+}
+
+// kubernetesCVEs lists notable vulnerabilities in the API server, kubelet
+// and volume plugins. Minor versions older than every fixed release never
+// received the fix and are reported as affected.
+var kubernetesCVEs = []kubernetesCVE{
+	{
+		ID: "CVE-2018-1002105", Severity: SeverityCritical,
+		Summary: "API server connection upgrade lets any user escalate to cluster-admin through aggregated APIs or pod exec",
+		Fixed:   []string{"1.10.11", "1.11.5", "1.12.3"},
+	},
+	{
+		ID: "CVE-2020-8558", Severity: SeverityHigh,
+		Summary: "kube-proxy route_localnet setting exposes node localhost services to adjacent hosts",
+		Fixed:   []string{"1.16.11", "1.17.7", "1.18.4"},
+	},
+	{
+		ID: "CVE-2021-25741", Severity: SeverityHigh,
+		Summary: "symlink exchange in subPath volume mounts gives containers access to the host filesystem",
+		Fixed:   []string{"1.19.15", "1.20.11", "1.21.5", "1.22.2"},
+	},
+	{
+		ID: "CVE-2022-3294", Severity: SeverityMedium,
+		Summary: "node address validation bypass lets node proxy requests reach the API server's private network",
+		Fixed:   []string{"1.22.16", "1.23.14", "1.24.8", "1.25.4"},
+	},
+	{
+		ID: "CVE-2023-3676", Severity: SeverityHigh,
+		Summary: "command injection through subPath on Windows nodes gives pod creators SYSTEM access",
+		Fixed:   []string{"1.24.17", "1.25.13", "1.26.8", "1.27.5"},
+		Windows: true,
+	},
+	{
+		ID: "CVE-2023-5528", Severity: SeverityHigh,
+		Summary: "command injection through local PersistentVolumes on Windows nodes gives PV creators SYSTEM access",
+		Fixed:   []string{"1.25.16", "1.26.11", "1.27.8", "1.28.4"},
+		Windows: true,
+	},
+	{
+		ID: "CVE-2024-3177", Severity: SeverityLow,
+		Summary: "ServiceAccount admission does not enforce mountable secrets for ephemeral and init containers",
+		Fixed:   []string{"1.27.13", "1.28.9", "1.29.4"},
+	},
+	{
+		ID: "CVE-2024-10220", Severity: SeverityHigh,
+		Summary: "gitRepo volumes run hooks from the cloned repository on the host, outside the container",
+		Fixed:   []string{"1.28.12", "1.29.7", "1.30.3"},
+	},
+}
+
+// affects reports whether a Kubernetes version is missing the fix
+func (c kubernetesCVE) affects(v *version.Version) bool {
+	oldestFixed := version.MustParseGeneric(c.Fixed[0])
+	if v.Major() == oldestFixed.Major() && v.Minor() < oldestFixed.Minor() {
+		return true
+	}
+	for _, fixed := range c.Fixed {
+		f := version.MustParseGeneric(fixed)
+		if v.Major() == f.Major() && v.Minor() == f.Minor() {
+			return v.LessThan(f)
+		}
+	}
+	return false
+}
+
+// endOfLife returns when a version's minor release stopped receiving patches,
+// or false when the release is newer than the table
+func endOfLife(v *version.Version) (time.Time, bool) {
+	oldest := version.MustParseGeneric(kubernetesEOL[0].Minor)
+	if v.Major() == oldest.Major() && v.Minor() < oldest.Minor() {
+		eol, _ := time.Parse(time.DateOnly, kubernetesEOL[0].Date)
+		return eol, true
+	}
+	for _, release := range kubernetesEOL {
+		minor := version.MustParseGeneric(release.Minor)
+		if minor.Major() == v.Major() && minor.Minor() == v.Minor() {
+			eol, _ := time.Parse(time.DateOnly, release.Date)
+			return eol, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// VersionScanner reports an API server version that is past end of life or
+// affected by known vulnerabilities
+type VersionScanner struct {
+	// Now returns the current time; nil uses time.Now
+	Now func() time.Time
+}
+
+// Name returns the scanner identifier
+func (s *VersionScanner) Name() string {
+	return "version"
+}
+
+// Resources returns nothing; the server version is recorded with every snapshot
+func (s *VersionScanner) Resources() []k8s.Resource {
+	return nil
+}
+
+// OptionalResources returns the resources the scanner reads when collected.
+// Without Nodes, vulnerabilities limited to Windows nodes are still reported.
+func (s *VersionScanner) OptionalResources() []k8s.Resource {
+	return []k8s.Resource{k8s.ResourceNodes}
+}
+
+// Scan runs the version checks. Snapshots taken without a server version,
+// such as older archives, are skipped.
+func (s *VersionScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	if snap.ServerVersion == "" {
+		return nil
+	}
+	v, err := version.ParseGeneric(snap.ServerVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse server version %q: %w", snap.ServerVersion, err)
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	rec.Scanned("Cluster", "", snap.Cluster)
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("The API server runs Kubernetes %s, %s", snap.ServerVersion, description),
+			Severity:    severity,
+			Resource:    "Cluster/" + snap.Cluster,
+			Remediation: remediation,
+			Category:    CategoryKubernetesVersion,
+		}
+	}
+
+	// Check: releases that no longer receive security patches
+	if eol, ok := endOfLife(v); ok && !now().Before(eol) {
+		rec.Fail(issue("HK-066", "End-of-Life Kubernetes Version",
+			fmt.Sprintf("which stopped receiving security patches on %s", eol.Format(time.DateOnly)),
+			SeverityHigh, "Upgrade the control plane and nodes to a supported Kubernetes minor release."))
+	} else {
+		rec.Pass("HK-066")
+	}
+
+	// Check: known vulnerabilities fixed in later patch releases
+	windows := hasWindowsNodes(snap)
+	for _, cve := range kubernetesCVEs {
+		if cve.affects(v) && (windows || !cve.Windows) {
+			rec.Fail(issue("HK-067", "Vulnerable Kubernetes Version",
+				fmt.Sprintf("which is affected by %s: %s", cve.ID, cve.Summary),
+				cve.Severity, fmt.Sprintf("Upgrade to a patch release that fixes %s: %s.", cve.ID, strings.Join(cve.Fixed, ", "))))
+		} else {
+			rec.Pass("HK-067")
+		}
+	}
+	return nil
+}
+
+// hasWindowsNodes reports whether the cluster may run Windows nodes, which is
+// assumed when Nodes were not collected
+func hasWindowsNodes(snap *k8s.Snapshot) bool {
+	if !snap.Has(k8s.ResourceNodes) {
+		return true
+	}
+	for _, node := range snap.Nodes {
+		if node.Status.NodeInfo.OperatingSystem == "windows" {
+			return true
+		}
+	}
+	return false
+}
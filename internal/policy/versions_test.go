@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestKubernetesCVEAffects(t *testing.T) {
+	cve := kubernetesCVE{ID: "CVE-TEST", Fixed: []string{"1.28.12", "1.29.7", "1.30.3"}}
+	tests := map[string]bool{
+		"v1.27.16":         true,  // older line never received the fix
+		"v1.28.11":         true,  // before the fixed patch
+		"v1.29.7":          false, // the fixed patch
+		"v1.30.4-eks-a737": false,
+		"v1.31.0":          false, // released after the fix
+	}
+	for v, want := range tests {
+		if got := cve.affects(version.MustParseGeneric(v)); got != want {
+			t.Errorf("affects(%s) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestVersionScanner(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC) }
+	scan := func(serverVersion string) map[string]int {
+		rec := NewRecorder()
+		snap := &k8s.Snapshot{Cluster: "prod", ServerVersion: serverVersion}
+		if err := (&VersionScanner{Now: now}).Scan(context.Background(), snap, rec); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return issueIDs(rec.Issues())
+	}
+
+	if ids := scan("v1.29.3"); ids["HK-066"] != 1 || ids["HK-067"] != 2 {
+		t.Errorf("expected 1.29.3 to be end of life and affected by 2 CVEs, got %v", ids)
+	}
+	if ids := scan("v1.34.1"); len(ids) != 0 {
+		t.Errorf("expected no issues for a supported release, got %v", ids)
+	}
+	if ids := scan(""); len(ids) != 0 {
+		t.Errorf("expected snapshots without a server version to be skipped, got %v", ids)
+	}
+	if ids := scan("v2.30.0"); ids["HK-066"] != 0 {
+		t.Errorf("expected only the matching major release to be end of life, got %v", ids)
+	}
+}
+
+func TestVersionScannerWindowsCVEs(t *testing.T) {
+	node := func(name, os string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{OperatingSystem: os}},
+		}
+	}
+	scan := func(snap *k8s.Snapshot) int {
+		snap.ServerVersion = "v1.27.4"
+		rec := NewRecorder()
+		if err := (&VersionScanner{}).Scan(context.Background(), snap, rec); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return issueIDs(rec.Issues())["HK-067"]
+	}
+
+	collected := []string{k8s.ResourceNodes.String()}
+	if got := scan(&k8s.Snapshot{Collected: collected, Nodes: []corev1.Node{node("linux-1", "linux")}}); got != 2 {
+		t.Errorf("expected Windows-only CVEs to be skipped on Linux clusters, got %d", got)
+	}
+	if got := scan(&k8s.Snapshot{Collected: collected, Nodes: []corev1.Node{node("linux-1", "linux"), node("win-1", "windows")}}); got != 4 {
+		t.Errorf("expected Windows-only CVEs with a Windows node, got %d", got)
+	}
+	if got := scan(&k8s.Snapshot{}); got != 4 {
+		t.Errorf("expected Windows-only CVEs when nodes were not collected, got %d", got)
+	}
+}
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"github.com/ismailtsdln/HardenaK8s/internal/vuln"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxListedCVEs bounds how many CVE IDs are spelled out in an issue description
+const maxListedCVEs = 5
+
+// VulnerabilityScanner matches running images against offline Trivy, Grype
+// or CycloneDX reports by digest and reports critical and high CVEs
+type VulnerabilityScanner struct {
+	DB *vuln.Database
+}
+
+// Name returns the scanner identifier
+func (s *VulnerabilityScanner) Name() string {
+	return "vulnerabilities"
+}
+
+// Resources returns the resources the vulnerability scanner reads
+func (s *VulnerabilityScanner) Resources() []k8s.Resource {
+	return append([]k8s.Resource{k8s.ResourcePods}, k8s.WorkloadResources...)
+}
+
+// runningDigests maps container names to the digest of the image the kubelet
+// actually pulled, falling back to a digest in the image reference itself
+func runningDigests(pod *corev1.Pod) map[string]string {
+	digests := map[string]string{}
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses, pod.Status.EphemeralContainerStatuses} {
+		for _, status := range statuses {
+			if digest := vuln.NormalizeDigest(status.ImageID); digest != "" {
+				digests[status.Name] = digest
+			}
+		}
+	}
+	return digests
+}
+
+// Scan reports vulnerable images once per workload and container
+func (s *VulnerabilityScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	seen := map[string]bool{}
+
+	for i := range snap.Pods {
+		pod := &snap.Pods[i]
+		rec.Scanned("Pod", pod.Namespace, pod.Name)
+
+		workload := snap.WorkloadFor(pod)
+		digests := runningDigests(pod)
+		for _, container := range podContainers(&pod.Spec) {
+			key := fmt.Sprintf("%s/%s/%s/%s", workload.Namespace, workload.Kind, workload.Name, container.Name)
+			if seen[key] {
+				continue
+			}
+
+			digest := digests[container.Name]
+			if digest == "" {
+				digest = vuln.NormalizeDigest(container.Image)
+			}
+			report := s.DB.Lookup(digest)
+			if report == nil {
+				// No data for this image; another replica may still have a status
+				continue
+			}
+			seen[key] = true
+
+			s.checkContainer(rec, workload, container, report)
+		}
+	}
+	return nil
+}
+
+func (s *VulnerabilityScanner) checkContainer(rec *Recorder, workload k8s.WorkloadRef, container podContainer, report *vuln.Report) {
+	found := report.AtLeast(vuln.SeverityHigh)
+
+	// Vulnerable images in privileged containers are tracked separately so
+	// they stand out and can be prioritized
+	id, title := "HK-008", "Vulnerable Container Image"
+	if isPrivileged(container.Container) {
+		id, title = "HK-009", "Vulnerable Image In Privileged Container"
+	}
+
+	if len(found) == 0 {
+		rec.Pass(id)
+		return
+	}
+
+	severity := SeverityHigh
+	critical := 0
+	var ids []string
+	for _, v := range found {
+		if v.Severity == vuln.SeverityCritical {
+			severity = SeverityCritical
+			critical++
+		}
+		if len(ids) < maxListedCVEs {
+			ids = append(ids, fmt.Sprintf("%s (%s %s)", v.ID, v.Package, v.InstalledVersion))
+		}
+	}
+	if id == "HK-009" {
+		severity = SeverityCritical
+	}
+
+	listed := strings.Join(ids, ", ")
+	if len(found) > len(ids) {
+		listed += fmt.Sprintf(" and %d more", len(found)-len(ids))
+	}
+
+	rec.Fail(Issue{
+		ID:    id,
+		Title: title,
+		Description: fmt.Sprintf("%s %s in namespace %s runs container %s with image %s that has %d critical and %d high vulnerabilities: %s",
+			workload.Kind, workload.Name, workload.Namespace, container.Name, container.Image, critical, len(found)-critical, listed),
+		Severity:      severity,
+		Resource:      workload.Kind + "/" + workload.Name,
+		Namespace:     workload.Namespace,
+		Remediation:   "Rebuild the image on patched base layers and packages, then redeploy the fixed digest.",
+		Category:      CategoryVulnerability,
+		Container:     container.Name,
+		ContainerType: container.Type,
+	})
+}
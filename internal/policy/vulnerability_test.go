@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	"github.com/ismailtsdln/HardenaK8s/internal/vuln"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVulnerabilityScanner(t *testing.T) {
+	const (
+		vulnerable = "sha256:aaaa"
+		clean      = "sha256:bbbb"
+	)
+	db := &vuln.Database{}
+	db.Add(&vuln.Report{Digests: []string{vulnerable}, Vulnerabilities: []vuln.Vulnerability{
+		{ID: "CVE-2024-0001", Package: "openssl", Severity: vuln.SeverityHigh},
+		{ID: "CVE-2024-0002", Package: "glibc", Severity: vuln.SeverityMedium},
+	}})
+	db.Add(&vuln.Report{Digests: []string{clean}})
+
+	privileged := true
+	snap := &k8s.Snapshot{Pods: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Image: "ghcr.io/acme/app:1.0"},
+			{Name: "agent", Image: "ghcr.io/acme/agent@" + vulnerable, SecurityContext: &corev1.SecurityContext{Privileged: &privileged}},
+			{Name: "sidecar", Image: "ghcr.io/acme/sidecar:2.0"},
+			{Name: "unknown", Image: "ghcr.io/acme/unknown:3.0"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ImageID: "docker-pullable://ghcr.io/acme/app@" + vulnerable},
+			{Name: "sidecar", ImageID: "docker-pullable://ghcr.io/acme/sidecar@" + clean},
+		}},
+	}}}
+
+	rec := NewRecorder()
+	scanner := &VulnerabilityScanner{DB: db}
+	if err := scanner.Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	issues := rec.Issues()
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %+v", len(issues), issues)
+	}
+	for _, issue := range issues {
+		switch issue.Container {
+		case "app":
+			if issue.ID != "HK-008" || issue.Severity != SeverityHigh {
+				t.Errorf("unexpected issue for app: %+v", issue)
+			}
+		case "agent":
+			if issue.ID != "HK-009" || issue.Severity != SeverityCritical {
+				t.Errorf("unexpected issue for agent: %+v", issue)
+			}
+		default:
+			t.Errorf("unexpected issue for container %s", issue.Container)
+		}
+		if !strings.Contains(issue.Description, "CVE-2024-0001") || strings.Contains(issue.Description, "CVE-2024-0002") {
+			t.Errorf("description should list only high and critical CVEs: %s", issue.Description)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// securityWebhookKeywords identify webhooks that enforce security policy,
+// matched against the configuration, webhook and backing service names
+var securityWebhookKeywords = []string{
+	"gatekeeper",
+	"kyverno",
+	"kubewarden",
+	"jspolicy",
+	"opa",
+	"policy",
+	"polaris",
+	"security",
+	"psp",
+	"pod-security",
+}
+
+// criticalNamespaces are the namespaces a policy webhook must not skip; a
+// workload created there would bypass every guardrail the webhook enforces
+var criticalNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease"}
+
+// admissionWebhook holds the fields shared by validating and mutating webhooks
+type admissionWebhook struct {
+	// Kind is the kind of the configuration the webhook belongs to
+	Kind              string
+	Configuration     string
+	Name              string
+	ClientConfig      admissionregistrationv1.WebhookClientConfig
+	FailurePolicy     *admissionregistrationv1.FailurePolicyType
+	NamespaceSelector *metav1.LabelSelector
+}
+
+// admissionWebhooks flattens every webhook configuration in the snapshot
+func admissionWebhooks(snap *k8s.Snapshot) []admissionWebhook {
+	var webhooks []admissionWebhook
+	for _, config := range snap.ValidatingWebhookConfigurations {
+		for _, w := range config.Webhooks {
+			webhooks = append(webhooks, admissionWebhook{
+				Kind:              "ValidatingWebhookConfiguration",
+				Configuration:     config.Name,
+				Name:              w.Name,
+				ClientConfig:      w.ClientConfig,
+				FailurePolicy:     w.FailurePolicy,
+				NamespaceSelector: w.NamespaceSelector,
+			})
+		}
+	}
+	for _, config := range snap.MutatingWebhookConfigurations {
+		for _, w := range config.Webhooks {
+			webhooks = append(webhooks, admissionWebhook{
+				Kind:              "MutatingWebhookConfiguration",
+				Configuration:     config.Name,
+				Name:              w.Name,
+				ClientConfig:      w.ClientConfig,
+				FailurePolicy:     w.FailurePolicy,
+				NamespaceSelector: w.NamespaceSelector,
+			})
+		}
+	}
+	return webhooks
+}
+
+// security reports whether the webhook looks like a security policy engine
+func (w admissionWebhook) security() bool {
+	names := []string{w.Configuration, w.Name}
+	if svc := w.ClientConfig.Service; svc != nil {
+		names = append(names, svc.Namespace, svc.Name)
+	}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		for _, keyword := range securityWebhookKeywords {
+			if strings.Contains(name, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exemptedNamespaces returns the critical namespaces the webhook's
+// namespaceSelector does not match. The webhook's own namespace is left out,
+// since a webhook that intercepts its own pods can lock itself out.
+func (w admissionWebhook) exemptedNamespaces(snap *k8s.Snapshot) []string {
+	if w.NamespaceSelector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(w.NamespaceSelector)
+	if err != nil || selector.Empty() {
+		return nil
+	}
+
+	var exempted []string
+	for _, name := range criticalNamespaces {
+		if svc := w.ClientConfig.Service; svc != nil && svc.Namespace == name {
+			continue
+		}
+		// The API server labels every namespace with its name, which is
+		// enough to evaluate most selectors when the namespace was not collected
+		nsLabels := labels.Set{corev1.LabelMetadataName: name}
+		if ns := snap.NamespaceByName(name); ns != nil {
+			nsLabels = labels.Set(ns.Labels)
+		}
+		if !selector.Matches(nsLabels) {
+			exempted = append(exempted, name)
+		}
+	}
+	return exempted
+}
+
+// WebhookScanner audits admission webhook configurations, whose mistakes
+// silently switch off the policies they are meant to enforce
+type WebhookScanner struct{}
+
+// Name returns the scanner identifier
+func (s *WebhookScanner) Name() string {
+	return "webhooks"
+}
+
+// Resources returns the resources the webhook scanner reads
+func (s *WebhookScanner) Resources() []k8s.Resource {
+	return []k8s.Resource{
+		k8s.ResourceValidatingWebhooks,
+		k8s.ResourceMutatingWebhooks,
+		k8s.ResourceServices,
+		k8s.ResourceNamespaces,
+	}
+}
+
+// Scan runs the admission webhook checks
+func (s *WebhookScanner) Scan(ctx context.Context, snap *k8s.Snapshot, rec *Recorder) error {
+	for _, config := range snap.ValidatingWebhookConfigurations {
+		rec.Scanned("ValidatingWebhookConfiguration", "", config.Name)
+	}
+	for _, config := range snap.MutatingWebhookConfigurations {
+		rec.Scanned("MutatingWebhookConfiguration", "", config.Name)
+	}
+
+	for _, webhook := range admissionWebhooks(snap) {
+		s.checkWebhook(rec, snap, webhook)
+	}
+	return nil
+}
+
+func (s *WebhookScanner) checkWebhook(rec *Recorder, snap *k8s.Snapshot, webhook admissionWebhook) {
+	issue := func(id, title, description string, severity Severity, remediation string) Issue {
+		return Issue{
+			ID:          id,
+			Title:       title,
+			Description: fmt.Sprintf("Webhook %s in %s %s %s", webhook.Name, webhook.Kind, webhook.Configuration, description),
+			Severity:    severity,
+			Resource:    webhook.Kind + "/" + webhook.Configuration,
+			Remediation: remediation,
+			Category:    CategoryAdmissionControl,
+		}
+	}
+	security := webhook.security()
+	failOpen := webhook.FailurePolicy != nil && *webhook.FailurePolicy == admissionregistrationv1.Ignore
+
+	if security {
+		// Check: policy webhooks that let requests through when they are down
+		if failOpen {
+			rec.Fail(issue("HK-044", "Security Webhook Fails Open",
+				"uses 'failurePolicy: Ignore', so requests are admitted unchecked whenever the webhook is unavailable",
+				SeverityHigh, "Set 'failurePolicy: Fail' and run the webhook with several replicas and a PodDisruptionBudget."))
+		} else {
+			rec.Pass("HK-044")
+		}
+
+		// Check: policy webhooks that skip namespaces where workloads can be created
+		if exempted := webhook.exemptedNamespaces(snap); len(exempted) > 0 {
+			rec.Fail(issue("HK-045", "Security Webhook Exempts Critical Namespaces",
+				fmt.Sprintf("has a namespaceSelector that skips namespaces: %s", strings.Join(exempted, ", ")),
+				SeverityMedium, "Narrow the namespaceSelector so only the webhook's own namespace is excluded, and restrict who can create workloads in exempted namespaces."))
+		} else {
+			rec.Pass("HK-045")
+		}
+	}
+
+	// Check: the backing service exists; only namespaces covered by the scan can be verified
+	if svc := webhook.ClientConfig.Service; svc != nil && (snap.Namespace == "" || snap.Namespace == svc.Namespace) {
+		if snap.Service(svc.Namespace, svc.Name) == nil {
+			severity := SeverityMedium
+			description := fmt.Sprintf("points at service %s/%s, which does not exist", svc.Namespace, svc.Name)
+			switch {
+			case security && failOpen:
+				severity = SeverityHigh
+				description += ", so the policy it enforces is silently skipped"
+			case !failOpen:
+				description += ", so matching requests are rejected"
+			}
+			rec.Fail(issue("HK-046", "Webhook Service Not Found", description,
+				severity, "Deploy the webhook service or delete the stale webhook configuration."))
+		} else {
+			rec.Pass("HK-046")
+		}
+	}
+
+	// Check: in-cluster webhooks need a CA bundle to verify the service certificate
+	if webhook.ClientConfig.Service != nil {
+		if len(webhook.ClientConfig.CABundle) == 0 {
+			rec.Fail(issue("HK-047", "Webhook Without caBundle",
+				"has no caBundle, so the API server cannot verify the webhook's serving certificate against its own CA",
+				SeverityMedium, "Set 'clientConfig.caBundle', or have cert-manager's CA injector populate it."))
+		} else {
+			rec.Pass("HK-047")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/HardenaK8s/internal/k8s"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWebhookScanner(t *testing.T) {
+	ignore := admissionregistrationv1.Ignore
+	fail := admissionregistrationv1.Fail
+	webhook := func(name, service string, policy *admissionregistrationv1.FailurePolicyType, caBundle []byte) admissionregistrationv1.ValidatingWebhook {
+		return admissionregistrationv1.ValidatingWebhook{
+			Name:          name,
+			FailurePolicy: policy,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service:  &admissionregistrationv1.ServiceReference{Namespace: "kyverno", Name: service},
+				CABundle: caBundle,
+			},
+		}
+	}
+
+	// Fails open, skips kube-system and default, and its service is gone
+	exempting := webhook("validate.kyverno.svc", "kyverno-old", &ignore, []byte("ca"))
+	exempting.NamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+		Key:      corev1.LabelMetadataName,
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{"kube-system", "default", "kyverno"},
+	}}}
+
+	// Not a policy engine, so only the service and CA checks apply
+	certs := webhook("certs.example.com", "checker", &ignore, nil)
+	certs.ClientConfig.Service.Namespace = "certs"
+
+	snap := &k8s.Snapshot{
+		Services: []corev1.Service{
+			{ObjectMeta: metav1.ObjectMeta{Name: "kyverno-svc", Namespace: "kyverno"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "checker", Namespace: "certs"}},
+		},
+		ValidatingWebhookConfigurations: []admissionregistrationv1.ValidatingWebhookConfiguration{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "kyverno-resource-validating"},
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{
+					exempting,
+					webhook("strict.kyverno.svc", "kyverno-svc", &fail, []byte("ca")),
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "cert-checker"},
+				Webhooks:   []admissionregistrationv1.ValidatingWebhook{certs},
+			},
+		},
+	}
+
+	rec := NewRecorder()
+	if err := (&WebhookScanner{}).Scan(context.Background(), snap, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIssueCounts(t, rec.Issues(), map[string]int{"HK-044": 1, "HK-045": 1, "HK-046": 1, "HK-047": 1})
+
+	for _, issue := range rec.Issues() {
+		switch issue.ID {
+		case "HK-045":
+			if want := "Webhook validate.kyverno.svc in ValidatingWebhookConfiguration kyverno-resource-validating has a namespaceSelector that skips namespaces: default, kube-system"; issue.Description != want {
+				t.Errorf("unexpected description %q", issue.Description)
+			}
+		case "HK-046":
+			if issue.Severity != SeverityHigh {
+				t.Errorf("expected a missing service behind a fail-open policy webhook to be %s, got %s", SeverityHigh, issue.Severity)
+			}
+		case "HK-047":
+			if issue.Resource != "ValidatingWebhookConfiguration/cert-checker" {
+				t.Errorf("expected the missing caBundle on cert-checker, got %s", issue.Resource)
+			}
+		}
+	}
+}
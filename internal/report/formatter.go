@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ismailtsdln/HardenaK8s/internal/policy"
 	"gopkg.in/yaml.v3"
@@ -33,7 +34,18 @@ type TextFormatter struct{}
 
 func (f *TextFormatter) Format(result *policy.Result) ([]byte, error) {
 	// This is typically handled by the CLI logic directly for vibrancy
-	return []byte("Summary: " + fmt.Sprintf("%d issues found", result.Stats.TotalIssues)), nil
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary: %d issues found, %d resources scanned, %d checks passed, %d checks failed",
+		result.Stats.TotalIssues, result.Stats.ResourcesScanned, result.Stats.ChecksPassed, result.Stats.ChecksFailed)
+
+	if result.Partial() {
+		fmt.Fprintf(&b, "\nIncomplete scan: %d scanner errors", len(result.Errors))
+		for _, scanErr := range result.Errors {
+			fmt.Fprintf(&b, "\n  %s [%s] namespaces=%s: %s",
+				scanErr.Scanner, scanErr.Reason, strings.Join(scanErr.Namespaces, ","), scanErr.Message)
+		}
+	}
+	return []byte(b.String()), nil
 }
 
 // SaveToFile writes the formatted report to a file
@@ -1,6 +1,7 @@
 package report
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ismailtsdln/HardenaK8s/internal/policy"
@@ -33,3 +34,61 @@ func TestGetFormatter(t *testing.T) {
 		t.Error("expected error for invalid formatter, got nil")
 	}
 }
+
+func TestHTMLFormatterDistinguishesEmptyScan(t *testing.T) {
+	formatter := &HTMLFormatter{}
+
+	empty, err := formatter.Format(&policy.Result{})
+	if err != nil {
+		t.Fatalf("failed to format HTML: %v", err)
+	}
+	if !strings.Contains(string(empty), "No resources were inspected") {
+		t.Error("expected a scan that inspected nothing to be called out")
+	}
+
+	clean, err := formatter.Format(&policy.Result{Stats: policy.Stats{ResourcesScanned: 3, ChecksPassed: 9}})
+	if err != nil {
+		t.Fatalf("failed to format HTML: %v", err)
+	}
+	if !strings.Contains(string(clean), "No security issues found") {
+		t.Error("expected a clean scan to be reported as hardened")
+	}
+}
+
+func TestTextFormatterListsScannerErrors(t *testing.T) {
+	result := &policy.Result{
+		Errors: []policy.ScanError{{
+			Scanner:    "pods",
+			Reason:     "Forbidden",
+			Namespaces: []string{"*"},
+			Message:    "pods is forbidden",
+		}},
+	}
+
+	data, err := (&TextFormatter{}).Format(result)
+	if err != nil {
+		t.Fatalf("failed to format text: %v", err)
+	}
+	if !strings.Contains(string(data), "Incomplete scan") || !strings.Contains(string(data), "pods [Forbidden]") {
+		t.Errorf("expected scanner errors in text output, got %q", data)
+	}
+}
+
+func TestHTMLFormatterRendersFleetComparison(t *testing.T) {
+	merged := policy.Merge(
+		&policy.Result{Cluster: "prod", Issues: []policy.Issue{{ID: "HK-001", Title: "Privileged Container Detected", Severity: policy.SeverityCritical}}},
+		&policy.Result{Cluster: "staging", Issues: []policy.Issue{{ID: "HK-001", Title: "Privileged Container Detected", Severity: policy.SeverityCritical}}},
+	)
+
+	data, err := (&HTMLFormatter{}).Format(merged)
+	if err != nil {
+		t.Fatalf("failed to format HTML: %v", err)
+	}
+
+	html := string(data)
+	for _, want := range []string{"Fleet Overview", "Findings Shared Across Clusters", "<th>prod</th>", "<th>staging</th>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q", want)
+		}
+	}
+}
@@ -118,6 +118,33 @@ const htmlTemplate = `
             border: 1px dashed var(--primary);
         }
         
+        table {
+            width: 100%;
+            border-collapse: collapse;
+            background: var(--card-bg);
+            border-radius: 1rem;
+            overflow: hidden;
+            margin-bottom: 3rem;
+        }
+
+        th, td {
+            padding: 0.75rem 1.5rem;
+            text-align: left;
+            border-bottom: 1px solid rgba(255,255,255,0.05);
+        }
+
+        th { color: var(--text-dim); font-size: 0.8rem; text-transform: uppercase; letter-spacing: 0.05em; }
+
+        .scan-errors {
+            background: rgba(239, 68, 68, 0.1);
+            border: 1px solid var(--critical);
+            border-radius: 1rem;
+            padding: 1.5rem;
+            margin-bottom: 3rem;
+        }
+
+        .scan-errors h2 { margin-top: 0; color: var(--critical); }
+
         .footer {
             text-align: center;
             margin-top: 5rem;
@@ -138,6 +165,14 @@ const htmlTemplate = `
                 <span class="stat-value">{{.Stats.TotalIssues}}</span>
                 <span class="stat-label">Total Issues</span>
             </div>
+            <div class="stat-card">
+                <span class="stat-value">{{.Stats.ResourcesScanned}}</span>
+                <span class="stat-label">Resources Scanned</span>
+            </div>
+            <div class="stat-card">
+                <span class="stat-value">{{.Stats.ChecksPassed}}</span>
+                <span class="stat-label">Checks Passed</span>
+            </div>
             {{range $sev, $count := .Stats.SeverityCount}}
             <div class="stat-card">
                 <span class="stat-value">{{$count}}</span>
@@ -146,6 +181,56 @@ const htmlTemplate = `
             {{end}}
         </div>
 
+        {{if .Errors}}
+        <div class="scan-errors">
+            <h2>Incomplete Scan</h2>
+            <p>The following scanners could not fully inspect the cluster. Findings below do not cover these gaps.</p>
+            <table>
+                <tr><th>Scanner</th><th>Reason</th><th>Resource</th><th>Namespaces</th><th>Message</th></tr>
+                {{range .Errors}}
+                <tr><td>{{if .Cluster}}{{.Cluster}}/{{end}}{{.Scanner}}</td><td>{{.Reason}}</td><td>{{.Resource}}</td><td>{{range $i, $ns := .Namespaces}}{{if $i}}, {{end}}{{$ns}}{{end}}</td><td>{{.Message}}</td></tr>
+                {{end}}
+            </table>
+        </div>
+        {{end}}
+
+        {{with .Fleet}}
+        <h2>Fleet Overview</h2>
+        <table>
+            <tr><th>Cluster</th><th>Score</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Risk</th></tr>
+            {{range .Clusters}}
+            <tr>
+                <td>{{.Cluster}}{{if .Partial}} <span class="HIGH">(partial)</span>{{end}}</td>
+                <td>{{.Score}}%</td>
+                <td class="CRITICAL">{{count .SeverityCount "CRITICAL"}}</td>
+                <td class="HIGH">{{count .SeverityCount "HIGH"}}</td>
+                <td class="MEDIUM">{{count .SeverityCount "MEDIUM"}}</td>
+                <td class="LOW">{{count .SeverityCount "LOW"}}</td>
+                <td>{{.Risk}}</td>
+            </tr>
+            {{end}}
+        </table>
+
+        {{if .CommonFindings}}
+        <h2>Findings Shared Across Clusters</h2>
+        <table>
+            <tr>
+                <th>Finding</th><th>Severity</th>
+                {{range .Clusters}}<th>{{.Cluster}}</th>{{end}}
+            </tr>
+            {{$clusters := .Clusters}}
+            {{range .CommonFindings}}
+            {{$finding := .}}
+            <tr>
+                <td>{{.ID}} {{.Title}}</td>
+                <td class="{{.Severity}}">{{.Severity}}</td>
+                {{range $clusters}}<td>{{if affects $finding .Cluster}}✖{{else}}✔{{end}}</td>{{end}}
+            </tr>
+            {{end}}
+        </table>
+        {{end}}
+        {{end}}
+
         <h2>Security Findings</h2>
         {{range .Issues}}
         <div class="issue-card {{.Severity}}">
@@ -154,7 +239,8 @@ const htmlTemplate = `
                 <span class="severity-badge">{{.Severity}}</span>
             </div>
             <div class="issue-body">
-                <p><strong>Resource:</strong> {{.Resource}} ({{.Namespace}})</p>
+                {{if .Cluster}}<p><strong>Cluster:</strong> {{.Cluster}}</p>{{end}}
+                <p><strong>Resource:</strong> {{.Resource}} ({{.Namespace}}){{if .Container}}, {{if and .ContainerType (ne .ContainerType "container")}}{{.ContainerType}} {{end}}container {{.Container}}{{end}}</p>
                 <p>{{.Description}}</p>
                 <div class="remediation">
                     <strong>Remediation:</strong> {{.Remediation}}
@@ -162,7 +248,39 @@ const htmlTemplate = `
             </div>
         </div>
         {{else}}
+        {{if .Errors}}
+        <p>No security issues found in the resources that could be inspected.</p>
+        {{else if .Stats.ResourcesScanned}}
         <p>No security issues found! Your cluster is hardened. 🛡️</p>
+        {{else}}
+        <p>No resources were inspected. Check the scanned namespace and the permissions of the scanning identity.</p>
+        {{end}}
+        {{end}}
+
+        {{if .Stats.Rules}}
+        <h2>Coverage</h2>
+        <table>
+            <tr><th>Rule</th><th>Evaluated</th><th>Passed</th><th>Failed</th></tr>
+            {{range .Stats.Rules}}
+            <tr><td>{{.ID}}</td><td>{{.Evaluated}}</td><td>{{.Passed}}</td><td>{{.Failed}}</td></tr>
+            {{end}}
+        </table>
+        <table>
+            <tr><th>Kind</th><th>Resources</th></tr>
+            {{range $kind, $count := .Stats.ResourcesByKind}}
+            <tr><td>{{$kind}}</td><td>{{$count}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+
+        {{if .Stats.Scanners}}
+        <h2>Scanner Timings</h2>
+        <table>
+            <tr><th>Scanner</th><th>Duration</th><th>Objects</th><th>Checks</th><th>Issues</th></tr>
+            {{range .Stats.Scanners}}
+            <tr><td>{{.Name}}</td><td>{{.Duration}}</td><td>{{.Objects}}</td><td>{{.Checks}}</td><td>{{.Issues}}</td></tr>
+            {{end}}
+        </table>
         {{end}}
 
         <div class="footer">
@@ -174,7 +292,19 @@ const htmlTemplate = `
 `
 
 func (f *HTMLFormatter) Format(result *policy.Result) ([]byte, error) {
-	tmpl, err := template.New("report").Parse(htmlTemplate)
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"count": func(counts map[policy.Severity]int, severity string) int {
+			return counts[policy.Severity(severity)]
+		},
+		"affects": func(finding policy.CommonFinding, cluster string) bool {
+			for _, c := range finding.Clusters {
+				if c == cluster {
+					return true
+				}
+			}
+			return false
+		},
+	}).Parse(htmlTemplate)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,349 @@
+package vuln
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severity levels as reported by the supported scanners, normalized to upper case
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityHigh     = "HIGH"
+	SeverityMedium   = "MEDIUM"
+	SeverityLow      = "LOW"
+	SeverityUnknown  = "UNKNOWN"
+)
+
+// Vulnerability is a single CVE affecting a package in an image
+type Vulnerability struct {
+	ID               string
+	Package          string
+	InstalledVersion string
+	FixedVersion     string
+	Severity         string
+}
+
+// Report holds the vulnerabilities found in one image
+type Report struct {
+	// Source is the file the report was loaded from
+	Source string
+	// Image is the image name as recorded by the scanner
+	Image string
+	// Digests are the manifest digests ("sha256:...") the report applies to
+	Digests         []string
+	Vulnerabilities []Vulnerability
+}
+
+// Database indexes reports by image digest
+type Database struct {
+	Reports  []*Report
+	byDigest map[string]*Report
+}
+
+// LoadDir reads every Trivy, Grype and CycloneDX JSON file in dir.
+// Files in other formats are skipped; malformed JSON is an error.
+func LoadDir(dir string) (*Database, error) {
+	db := &Database{byDigest: map[string]*Report{}}
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		report, err := Parse(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if report == nil {
+			return nil
+		}
+		report.Source = path
+		db.Add(report)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Add indexes a report under each of its digests
+func (db *Database) Add(report *Report) {
+	if db.byDigest == nil {
+		db.byDigest = map[string]*Report{}
+	}
+	db.Reports = append(db.Reports, report)
+	for _, digest := range report.Digests {
+		db.byDigest[digest] = report
+	}
+}
+
+// Lookup returns the report for an image digest, or nil when none was loaded
+func (db *Database) Lookup(digest string) *Report {
+	return db.byDigest[NormalizeDigest(digest)]
+}
+
+// NormalizeDigest extracts the "sha256:..." part from references such as
+// "docker-pullable://nginx@sha256:..." or "repo@sha256:...". It returns an
+// empty string when the input does not contain a digest.
+func NormalizeDigest(ref string) string {
+	if at := strings.LastIndex(ref, "@"); at >= 0 {
+		ref = ref[at+1:]
+	}
+	if !strings.HasPrefix(ref, "sha256:") {
+		return ""
+	}
+	return ref
+}
+
+// Parse detects the format of a scanner report and decodes it. It returns
+// nil without error for JSON documents that are not vulnerability reports,
+// including documents that are not objects, such as arrays.
+func Parse(data []byte) (*Report, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	switch {
+	case probe["bomFormat"] != nil:
+		return parseCycloneDX(data)
+	case probe["matches"] != nil && probe["source"] != nil:
+		return parseGrype(data)
+	case probe["ArtifactName"] != nil && probe["Results"] != nil:
+		return parseTrivy(data)
+	default:
+		return nil, nil
+	}
+}
+
+func parseTrivy(data []byte) (*Report, error) {
+	var doc struct {
+		ArtifactName string
+		Metadata     struct {
+			RepoDigests []string
+		}
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID  string
+				PkgName          string
+				InstalledVersion string
+				FixedVersion     string
+				Severity         string
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Image: doc.ArtifactName}
+	for _, ref := range doc.Metadata.RepoDigests {
+		report.addDigest(ref)
+	}
+	report.addDigest(doc.ArtifactName)
+
+	for _, result := range doc.Results {
+		for _, v := range result.Vulnerabilities {
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				ID:               v.VulnerabilityID,
+				Package:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				Severity:         normalizeSeverity(v.Severity),
+			})
+		}
+	}
+	return report, nil
+}
+
+func parseGrype(data []byte) (*Report, error) {
+	var doc struct {
+		Source struct {
+			Target struct {
+				UserInput      string   `json:"userInput"`
+				RepoDigests    []string `json:"repoDigests"`
+				ManifestDigest string   `json:"manifestDigest"`
+			} `json:"target"`
+		} `json:"source"`
+		Matches []struct {
+			Vulnerability struct {
+				ID       string `json:"id"`
+				Severity string `json:"severity"`
+				Fix      struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"artifact"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	target := doc.Source.Target
+	report := &Report{Image: target.UserInput}
+	for _, ref := range target.RepoDigests {
+		report.addDigest(ref)
+	}
+	report.addDigest(target.ManifestDigest)
+	report.addDigest(target.UserInput)
+
+	for _, m := range doc.Matches {
+		report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+			ID:               m.Vulnerability.ID,
+			Package:          m.Artifact.Name,
+			InstalledVersion: m.Artifact.Version,
+			FixedVersion:     strings.Join(m.Vulnerability.Fix.Versions, ", "),
+			Severity:         normalizeSeverity(m.Vulnerability.Severity),
+		})
+	}
+	return report, nil
+}
+
+func parseCycloneDX(data []byte) (*Report, error) {
+	type component struct {
+		BOMRef  string `json:"bom-ref"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		PURL    string `json:"purl"`
+	}
+	var doc struct {
+		Metadata struct {
+			Component component `json:"component"`
+		} `json:"metadata"`
+		Components      []component `json:"components"`
+		Vulnerabilities []struct {
+			ID      string `json:"id"`
+			Ratings []struct {
+				Severity string `json:"severity"`
+			} `json:"ratings"`
+			Affects []struct {
+				Ref string `json:"ref"`
+			} `json:"affects"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	// Container SBOMs carry the image digest as the component version or in
+	// an OCI package URL such as pkg:oci/app@sha256%3A...
+	root := doc.Metadata.Component
+	report := &Report{Image: root.Name}
+	report.addDigest(root.Version)
+	if strings.HasPrefix(root.PURL, "pkg:oci/") {
+		if unescaped, err := url.PathUnescape(root.PURL); err == nil {
+			if q := strings.Index(unescaped, "?"); q >= 0 {
+				unescaped = unescaped[:q]
+			}
+			report.addDigest(unescaped)
+		}
+	}
+
+	components := map[string]component{}
+	for _, c := range doc.Components {
+		components[c.BOMRef] = c
+	}
+
+	for _, v := range doc.Vulnerabilities {
+		severity := SeverityUnknown
+		for _, rating := range v.Ratings {
+			if s := normalizeSeverity(rating.Severity); severityRank(s) > severityRank(severity) {
+				severity = s
+			}
+		}
+
+		for _, affected := range v.Affects {
+			c, ok := components[affected.Ref]
+			if !ok {
+				c = component{Name: affected.Ref}
+			}
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				ID:               v.ID,
+				Package:          c.Name,
+				InstalledVersion: c.Version,
+				Severity:         severity,
+			})
+		}
+	}
+	return report, nil
+}
+
+func (r *Report) addDigest(ref string) {
+	digest := NormalizeDigest(ref)
+	if digest == "" {
+		return
+	}
+	for _, existing := range r.Digests {
+		if existing == digest {
+			return
+		}
+	}
+	r.Digests = append(r.Digests, digest)
+}
+
+func normalizeSeverity(severity string) string {
+	switch s := strings.ToUpper(severity); s {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow:
+		return s
+	case "NEGLIGIBLE", "INFO", "NONE":
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 4
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast returns the vulnerabilities at or above the given severity,
+// most severe first and de-duplicated by ID
+func (r *Report) AtLeast(severity string) []Vulnerability {
+	threshold := severityRank(severity)
+	seen := map[string]bool{}
+	var matched []Vulnerability
+	for _, v := range r.Vulnerabilities {
+		if severityRank(v.Severity) < threshold || seen[v.ID] {
+			continue
+		}
+		seen[v.ID] = true
+		matched = append(matched, v)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return severityRank(matched[i].Severity) > severityRank(matched[j].Severity)
+	})
+	return matched
+}
@@ -0,0 +1,100 @@
+package vuln
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const digest = "sha256:0123456789abcdef"
+
+func TestNormalizeDigest(t *testing.T) {
+	tests := map[string]string{
+		"docker-pullable://nginx@" + digest: digest,
+		"ghcr.io/acme/app@" + digest:        digest,
+		digest:                              digest,
+		"nginx:1.25":                        "",
+		"":                                  "",
+	}
+	for ref, want := range tests {
+		if got := NormalizeDigest(ref); got != want {
+			t.Errorf("NormalizeDigest(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	tests := map[string]string{
+		"trivy": `{"ArtifactName":"nginx:1.25","Metadata":{"RepoDigests":["nginx@` + digest + `"]},
+			"Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-1","PkgName":"openssl","InstalledVersion":"3.0.1","Severity":"CRITICAL"}]}]}`,
+		"grype": `{"source":{"target":{"userInput":"nginx:1.25","repoDigests":["nginx@` + digest + `"]}},
+			"matches":[{"vulnerability":{"id":"CVE-1","severity":"Critical"},"artifact":{"name":"openssl","version":"3.0.1"}}]}`,
+		"cyclonedx": `{"bomFormat":"CycloneDX","metadata":{"component":{"name":"nginx","version":"` + digest + `"}},
+			"components":[{"bom-ref":"pkg-1","name":"openssl","version":"3.0.1"}],
+			"vulnerabilities":[{"id":"CVE-1","ratings":[{"severity":"high"},{"severity":"critical"}],"affects":[{"ref":"pkg-1"}]}]}`,
+	}
+
+	for format, doc := range tests {
+		report, err := Parse([]byte(doc))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if report == nil {
+			t.Fatalf("%s: format not detected", format)
+		}
+		if len(report.Digests) != 1 || report.Digests[0] != digest {
+			t.Errorf("%s: expected digest %s, got %v", format, digest, report.Digests)
+		}
+		want := Vulnerability{ID: "CVE-1", Package: "openssl", InstalledVersion: "3.0.1", Severity: SeverityCritical}
+		if len(report.Vulnerabilities) != 1 || report.Vulnerabilities[0] != want {
+			t.Errorf("%s: expected %+v, got %+v", format, want, report.Vulnerabilities)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	report := &Report{Vulnerabilities: []Vulnerability{
+		{ID: "CVE-low", Severity: SeverityLow},
+		{ID: "CVE-high", Severity: SeverityHigh},
+		{ID: "CVE-crit", Severity: SeverityCritical, Package: "a"},
+		{ID: "CVE-crit", Severity: SeverityCritical, Package: "b"},
+	}}
+
+	got := report.AtLeast(SeverityHigh)
+	if len(got) != 2 || got[0].ID != "CVE-crit" || got[1].ID != "CVE-high" {
+		t.Errorf("unexpected vulnerabilities: %+v", got)
+	}
+}
+
+func TestLoadDirSkipsUnknownDocuments(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app.json":   `{"ArtifactName":"app@` + digest + `","Results":[]}`,
+		"other.json": `{"kind":"ConfigMap"}`,
+		"list.json":  `[{"ArtifactName":"app"}]`,
+		"notes.txt":  `not json`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(db.Reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(db.Reports))
+	}
+	if db.Lookup("docker-pullable://app@"+digest) == nil {
+		t.Error("expected report to be found by image ID")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(dir); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+}